@@ -2,11 +2,18 @@ package main
 
 import (
 	"github.com/alecthomas/kong"
+	"github.com/core-stack/zetten-cli/internal/cli/commands/auth"
+	"github.com/core-stack/zetten-cli/internal/cli/commands/info"
 	"github.com/core-stack/zetten-cli/internal/cli/commands/initialize"
 	"github.com/core-stack/zetten-cli/internal/cli/commands/install"
+	"github.com/core-stack/zetten-cli/internal/cli/commands/list"
+	"github.com/core-stack/zetten-cli/internal/cli/commands/patch"
 	"github.com/core-stack/zetten-cli/internal/cli/commands/promote"
+	"github.com/core-stack/zetten-cli/internal/cli/commands/search"
+	"github.com/core-stack/zetten-cli/internal/cli/commands/status"
 	"github.com/core-stack/zetten-cli/internal/cli/commands/sync"
 	"github.com/core-stack/zetten-cli/internal/cli/commands/uninstall"
+	"github.com/core-stack/zetten-cli/internal/cli/commands/verify"
 )
 
 var cli struct {
@@ -16,6 +23,13 @@ var cli struct {
 	Update    install.InstallCommand     `cmd:"" help:"Update a package."`
 	Sync      sync.SyncCommand           `cmd:"" help:"Sync packages."`
 	Promote   promote.PromoteCommand     `cmd:"" help:"Promote a package."`
+	Status    status.StatusCommand       `cmd:"" help:"Show local changes of installed packages."`
+	Patch     patch.PatchCommand         `cmd:"" help:"Save local changes of a package as a patch applied on every sync."`
+	Verify    verify.VerifyCommand       `cmd:"" help:"Check the integrity of the package store."`
+	Search    search.SearchCommand       `cmd:"" help:"Search the package registries."`
+	Info      info.InfoCommand           `cmd:"" help:"Show details about a package without installing it."`
+	List      list.ListCommand           `cmd:"" help:"List installed dependencies."`
+	Auth      auth.AuthCommand           `cmd:"" help:"Manage credentials used to reach package repositories."`
 }
 
 func main() {
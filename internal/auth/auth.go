@@ -2,13 +2,12 @@ package auth
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
-	"strings"
+	"strconv"
+	"sync"
 
 	"github.com/core-stack/zetten-cli/internal/util"
-	"github.com/goccy/go-yaml"
 )
 
 var DEFAULT_AUTH_FILE_NAME = "zetten-auth.yml"
@@ -16,47 +15,99 @@ var DEFAULT_AUTH_FILE_NAME = "zetten-auth.yml"
 type AuthConfig struct {
 	Method      string `yaml:"method"`
 	Credentials string `yaml:"credentials"`
+
+	// ssh only
+	User       string `yaml:"user,omitempty"`
+	Port       int    `yaml:"port,omitempty"`
+	Passphrase string `yaml:"passphrase,omitempty"`
+	KnownHosts string `yaml:"known_hosts,omitempty"`
+	// InsecureIgnoreHostKey turns host key verification off
+	InsecureIgnoreHostKey bool `yaml:"insecure_ignore_host_key,omitempty"`
 }
 
 type AuthMap map[string]AuthConfig
 
 type AuthConfigLoader struct {
 	Configs AuthMap
+	// Sources is the auth file each entry of Configs was read from
+	Sources map[string]string
+	// Netrc is the .netrc file looked up when no entry matches, none when
+	// empty.
+	Netrc string
+
+	// paths are read into Configs on first use, so encrypted files are only
+	// decrypted when credentials are needed
+	paths []string
+	// local is the path of the auth file of the current directory
+	local   string
+	loadErr error
+	once    sync.Once
 }
 
+// Load reads the auth files of the loader, once. Configs is complete after
+// it.
+func (l *AuthConfigLoader) Load() error {
+	l.once.Do(func() {
+		if l.Configs == nil {
+			l.Configs = make(AuthMap)
+		}
+		if l.Sources == nil {
+			l.Sources = make(map[string]string)
+		}
+		for _, path := range l.paths {
+			auths, err := LoadAuthFile(path)
+			if err != nil {
+				l.loadErr = err
+				return
+			}
+			for key := range auths {
+				if _, exists := l.Sources[key]; !exists {
+					l.Sources[key] = path
+				}
+			}
+			l.Configs = util.MergeMap(l.Configs, auths)
+		}
+	})
+	return l.loadErr
+}
+
+// FindAuth returns the resolved credentials of the most specific entry that
+// applies to repoUrl, falling back to .netrc for http(s) urls.
 func (l *AuthConfigLoader) FindAuth(repoUrl string) (*AuthConfig, error) {
-	u, err := url.Parse(repoUrl)
+	_, cfg, err := l.Which(repoUrl)
+	return cfg, err
+}
+
+// Which is FindAuth also returning the key of the entry used, "netrc" for
+// the .netrc fallback.
+func (l *AuthConfigLoader) Which(repoUrl string) (string, *AuthConfig, error) {
+	if err := l.Load(); err != nil {
+		return "", nil, err
+	}
+	target, err := ParseTarget(repoUrl)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse repo URL: %w", err)
+		return "", nil, err
 	}
-
-	host := u.Host
-	urlPaths := strings.Split(u.Path, "/")
-	// verify decremental repository path
-	for i := len(urlPaths); i >= 1; i-- {
-		path := urlPaths[:i]
-		cfg, find := l.LoadByHostPath(host, strings.Join(path, "/"))
-		if find {
-			return cfg, nil
+	keys, err := l.Matches(repoUrl)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(keys) > 0 {
+		key := keys[0]
+		cfg := l.Configs[key]
+		if cfg.Method == GIT_CREDENTIAL_METHOD {
+			filled, err := gitCredentialFill(target.url, cfg.Credentials)
+			return key, filled, err
 		}
+		resolved, err := cfg.resolve(l.trusts(key))
+		return key, resolved, err
 	}
-	return nil, ErrNoAuthConfigFound
-}
-
-func (l *AuthConfigLoader) LoadByHostPath(host string, path string) (*AuthConfig, bool) {
-	hostPath := fmt.Sprintf("%s%s", host, path)
-
-	// find config by host path
-	cfg, exists := util.FindInMap(l.Configs,
-		func(k string, v AuthConfig) bool {
-			return k == hostPath
-		},
-	)
-	if exists {
-		return &cfg, true
-	} else {
-		return nil, false
+	if l.Netrc != "" && (target.Scheme == "http" || target.Scheme == "https") {
+		if cfg, found := netrcAuth(l.Netrc, target.Host); found {
+			return "netrc", cfg, nil
+		}
 	}
+	return "", nil, ErrNoAuthConfigFound
 }
 
 func NewAuthConfigLoader(fileName string) *AuthConfigLoader {
@@ -67,23 +118,18 @@ func NewAuthConfigLoader(fileName string) *AuthConfigLoader {
 		fileName, // local
 		filepath.Join(os.Getenv("HOME"), fmt.Sprintf(".zetten/%s", fileName)), // global
 	}
-	configs := make(AuthMap)
-	for _, path := range paths {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-
-		var auths AuthMap
-		if err := yaml.Unmarshal(data, &auths); err != nil {
-			fmt.Printf("invalid YAML in %s: %v\n", path, err)
-			continue
-		}
+	return &AuthConfigLoader{paths: paths, local: fileName, Netrc: netrcPath()}
+}
 
-		configs = util.MergeMap(configs, auths)
+// trusts reports whether the entry key may reference secrets and relax host
+// key verification. Entries of the local auth file only do when
+// AUTH_TRUST_LOCAL_ENV is set.
+func (l *AuthConfigLoader) trusts(key string) bool {
+	if l.local == "" || l.Sources[key] != l.local {
+		return true
 	}
-
-	return &AuthConfigLoader{Configs: configs}
+	trust, _ := strconv.ParseBool(os.Getenv(AUTH_TRUST_LOCAL_ENV))
+	return trust
 }
 
 var Loader = NewAuthConfigLoader(DEFAULT_AUTH_FILE_NAME)
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// An encrypted auth file holds the YAML of its entries sealed with NaCl
+// secretbox, under a key read from a key file or derived from a passphrase
+// with scrypt:
+//
+//	encrypted:
+//	  kdf: scrypt
+//	  salt: <base64>
+//	  data: <base64 nonce and sealed box>
+type encryptedFile struct {
+	Encrypted *envelope `yaml:"encrypted"`
+}
+
+type envelope struct {
+	Kdf  string `yaml:"kdf"`
+	Salt string `yaml:"salt,omitempty"`
+	Data string `yaml:"data"`
+}
+
+const (
+	KeyFileKdf    = "keyfile"
+	PassphraseKdf = "scrypt"
+
+	AUTH_PASSPHRASE_ENV = "ZETTEN_AUTH_PASSPHRASE"
+	keySize             = 32
+	nonceSize           = 24
+)
+
+// KeyFilePath is the key used by keyfile encrypted auth files.
+var KeyFilePath = filepath.Join(os.Getenv("HOME"), ".zetten", "auth.key")
+
+// PromptPassphrase asks for the passphrase of scrypt encrypted auth files
+// when ZETTEN_AUTH_PASSPHRASE is not set. The CLI replaces it with an
+// interactive prompt.
+var PromptPassphrase = func(path string) (string, error) {
+	return "", fmt.Errorf("%s is encrypted, set %s to decrypt it", path, AUTH_PASSPHRASE_ENV)
+}
+
+// PromptNewPassphrase asks for the passphrase a file is first encrypted with.
+// The CLI replaces it with a prompt asking twice.
+var PromptNewPassphrase = func(path string) (string, error) {
+	return PromptPassphrase(path)
+}
+
+// passphrases caches the passphrase given for each file, so it is asked for
+// once per run.
+var passphrases = map[string]string{}
+
+// EncryptionOf returns the kdf an auth file is encrypted with, "" for plain
+// or missing files.
+func EncryptionOf(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if env := parseEnvelope(data); env != nil {
+		return env.Kdf
+	}
+	return ""
+}
+
+func parseEnvelope(data []byte) *envelope {
+	var file encryptedFile
+	if err := yaml.Unmarshal(data, &file); err != nil || file.Encrypted == nil || file.Encrypted.Data == "" {
+		return nil
+	}
+	return file.Encrypted
+}
+
+func decrypt(path string, env *envelope) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(env.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt in %s: %w", path, err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil || len(sealed) < nonceSize {
+		return nil, fmt.Errorf("invalid encrypted data in %s", path)
+	}
+	key, err := encryptionKey(path, env.Kdf, salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], sealed[:nonceSize])
+	plain, ok := secretbox.Open(nil, sealed[nonceSize:], &nonce, key)
+	if !ok {
+		delete(passphrases, path)
+		return nil, fmt.Errorf("failed to decrypt %s: wrong key or passphrase", path)
+	}
+	return plain, nil
+}
+
+func encrypt(path, kdf string, plain []byte) ([]byte, error) {
+	var salt []byte
+	if kdf == PassphraseKdf {
+		if err := choosePassphrase(path); err != nil {
+			return nil, err
+		}
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+	}
+	key, err := encryptionKey(path, kdf, salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	sealed := secretbox.Seal(nonce[:], plain, &nonce, key)
+	return yaml.Marshal(encryptedFile{Encrypted: &envelope{
+		Kdf:  kdf,
+		Salt: base64.StdEncoding.EncodeToString(salt),
+		Data: base64.StdEncoding.EncodeToString(sealed),
+	}})
+}
+
+func encryptionKey(path, kdf string, salt []byte) (*[keySize]byte, error) {
+	var key [keySize]byte
+	switch kdf {
+	case KeyFileKdf:
+		data, err := os.ReadFile(KeyFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read auth key: %w", err)
+		}
+		if len(data) != keySize {
+			return nil, fmt.Errorf("invalid auth key %s: expected %d bytes", KeyFilePath, keySize)
+		}
+		copy(key[:], data)
+	case PassphraseKdf:
+		passphrase, err := passphraseFor(path)
+		if err != nil {
+			return nil, err
+		}
+		derived, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, keySize)
+		if err != nil {
+			return nil, err
+		}
+		copy(key[:], derived)
+	default:
+		return nil, fmt.Errorf("unknown auth file encryption %q in %s", kdf, path)
+	}
+	return &key, nil
+}
+
+func passphraseFor(path string) (string, error) {
+	if passphrase, ok := os.LookupEnv(AUTH_PASSPHRASE_ENV); ok {
+		return passphrase, nil
+	}
+	if passphrase, ok := passphrases[path]; ok {
+		return passphrase, nil
+	}
+	passphrase, err := PromptPassphrase(path)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("an empty passphrase cannot encrypt %s", path)
+	}
+	passphrases[path] = passphrase
+	return passphrase, nil
+}
+
+// choosePassphrase asks for a new passphrase for path unless one is set in the
+// environment or was given to decrypt it.
+func choosePassphrase(path string) error {
+	if _, ok := os.LookupEnv(AUTH_PASSPHRASE_ENV); ok {
+		return nil
+	}
+	if _, ok := passphrases[path]; ok {
+		return nil
+	}
+	passphrase, err := PromptNewPassphrase(path)
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return fmt.Errorf("an empty passphrase cannot encrypt %s", path)
+	}
+	passphrases[path] = passphrase
+	return nil
+}
+
+// CreateKeyFile writes a random key to KeyFilePath unless one exists.
+func CreateKeyFile() error {
+	if _, err := os.Stat(KeyFilePath); err == nil {
+		return nil
+	}
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(KeyFilePath), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(KeyFilePath, key, 0600)
+}
+
+// EncryptAuthFile rewrites the auth file at path encrypted with kdf, or in
+// plain text when kdf is empty.
+func EncryptAuthFile(path, kdf string) error {
+	auths, err := LoadAuthFile(path)
+	if err != nil {
+		return err
+	}
+	return writeAuthFile(path, auths, kdf)
+}
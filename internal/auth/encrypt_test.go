@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptAuthFile_Passphrase(t *testing.T) {
+	t.Setenv(AUTH_PASSPHRASE_ENV, "correct horse")
+	path := filepath.Join(t.TempDir(), DEFAULT_AUTH_FILE_NAME)
+	auths := AuthMap{"github.com/org": {Method: "token", Credentials: "ghp_secret"}}
+	require.NoError(t, SaveAuthFile(path, auths))
+
+	require.NoError(t, EncryptAuthFile(path, PassphraseKdf))
+	assert.Equal(t, PassphraseKdf, EncryptionOf(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ghp_secret")
+
+	loaded, err := LoadAuthFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, auths, loaded)
+
+	// saving keeps the file encrypted
+	loaded["gitlab.com"] = AuthConfig{Method: "token", Credentials: "glpat"}
+	require.NoError(t, SaveAuthFile(path, loaded))
+	assert.Equal(t, PassphraseKdf, EncryptionOf(path))
+
+	t.Setenv(AUTH_PASSPHRASE_ENV, "wrong")
+	_, err = LoadAuthFile(path)
+	assert.Error(t, err)
+}
+
+func TestEncryptAuthFile_KeyFile(t *testing.T) {
+	dir := t.TempDir()
+	previous := KeyFilePath
+	KeyFilePath = filepath.Join(dir, "auth.key")
+	t.Cleanup(func() { KeyFilePath = previous })
+
+	path := filepath.Join(dir, DEFAULT_AUTH_FILE_NAME)
+	require.NoError(t, SaveAuthFile(path, AuthMap{"github.com": {Method: "token", Credentials: "ghp_secret"}}))
+	require.NoError(t, CreateKeyFile())
+	require.NoError(t, EncryptAuthFile(path, KeyFileKdf))
+
+	loader := &AuthConfigLoader{paths: []string{path}}
+	cfg, err := loader.FindAuth("https://github.com/org/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_secret", cfg.Credentials)
+
+	require.NoError(t, EncryptAuthFile(path, ""))
+	assert.Equal(t, "", EncryptionOf(path))
+}
+
+func TestEncryptAuthFile_NewPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DEFAULT_AUTH_FILE_NAME)
+	require.NoError(t, SaveAuthFile(path, AuthMap{"github.com": {Method: "token", Credentials: "ghp_secret"}}))
+	plain, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	previous := PromptNewPassphrase
+	t.Cleanup(func() { PromptNewPassphrase = previous })
+	PromptNewPassphrase = func(string) (string, error) {
+		return "", errors.New("passphrases do not match")
+	}
+	assert.Error(t, EncryptAuthFile(path, PassphraseKdf))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plain, data)
+
+	PromptNewPassphrase = func(string) (string, error) { return "correct horse", nil }
+	require.NoError(t, EncryptAuthFile(path, PassphraseKdf))
+	assert.Equal(t, PassphraseKdf, EncryptionOf(path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	delete(passphrases, path)
+}
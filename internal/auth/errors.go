@@ -4,4 +4,6 @@ import "errors"
 
 var (
 	ErrNoAuthConfigFound = errors.New("no auth config found")
+	ErrUnresolvedSecret  = errors.New("credentials reference could not be resolved")
+	ErrUntrustedHostKey  = errors.New("host key settings of an untrusted auth file")
 )
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/goccy/go-yaml"
+)
+
+type Scope string
+
+const (
+	// LocalScope is the auth file of the current directory, which wins over
+	// the global one.
+	LocalScope Scope = "local"
+	// GlobalScope is the auth file under ~/.zetten.
+	GlobalScope Scope = "global"
+)
+
+var Methods = []string{"token", "basic", "ssh", "ssh-agent", GIT_CREDENTIAL_METHOD, "none"}
+
+// ScopePath returns the auth file of scope.
+func ScopePath(scope Scope, fileName string) string {
+	if fileName == "" {
+		fileName = DEFAULT_AUTH_FILE_NAME
+	}
+	if scope == GlobalScope {
+		return filepath.Join(os.Getenv("HOME"), ".zetten", fileName)
+	}
+	return fileName
+}
+
+// LoadAuthFile reads the entries of a single auth file, decrypting it when it
+// is encrypted. A missing file has no entries.
+func LoadAuthFile(path string) (AuthMap, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return AuthMap{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if env := parseEnvelope(data); env != nil {
+		if data, err = decrypt(path, env); err != nil {
+			return nil, err
+		}
+	}
+	auths := AuthMap{}
+	if err := yaml.Unmarshal(data, &auths); err != nil {
+		return nil, fmt.Errorf("invalid YAML in %s: %w", path, err)
+	}
+	return auths, nil
+}
+
+// SaveAuthFile writes auths to path, readable by the owner only. An
+// encrypted file stays encrypted the same way.
+func SaveAuthFile(path string, auths AuthMap) error {
+	return writeAuthFile(path, auths, EncryptionOf(path))
+}
+
+func writeAuthFile(path string, auths AuthMap, kdf string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if kdf == "" {
+		data, err := yaml.MarshalWithOptions(auths, yaml.Indent(4))
+		if err != nil {
+			return err
+		}
+		return util.WriteFileAtomic(path, data, 0600)
+	}
+	plain, err := yaml.Marshal(auths)
+	if err != nil {
+		return err
+	}
+	data, err := encrypt(path, kdf, plain)
+	if err != nil {
+		return err
+	}
+	// a failed write must not leave the credentials truncated
+	return util.WriteFileAtomic(path, data, 0600)
+}
+
+// Keys returns the host/path keys of auths, sorted.
+func (m AuthMap) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NormalizeKey turns a url or host/path into the key auth entries are stored
+// under, dropping user info and trailing slashes, e.g. ssh://git@github.com/org/
+// into ssh://github.com/org. See match.go for the key syntax.
+func NormalizeKey(target string) string {
+	scheme, rest, found := strings.Cut(target, "://")
+	if !found {
+		scheme, rest = "", target
+	}
+	if at := strings.Index(rest, "@"); at >= 0 && at < strings.Index(rest+"/", "/") {
+		rest = rest[at+1:]
+	}
+	rest = strings.TrimSuffix(strings.TrimSuffix(rest, "/"), ".git")
+	if scheme != "" {
+		return scheme + "://" + rest
+	}
+	return rest
+}
+
+func (c AuthConfig) Validate() error {
+	for _, method := range Methods {
+		if c.Method == method {
+			if method == "basic" && !IsReference(c.Credentials) && !strings.Contains(c.Credentials, ":") {
+				return fmt.Errorf("basic auth credentials must be in format 'username:password'")
+			}
+			if method == "ssh" && c.Credentials == "" {
+				return fmt.Errorf("ssh auth credentials must be the path of a private key")
+			}
+			if c.Port < 0 || c.Port > 65535 {
+				return fmt.Errorf("invalid ssh port %d", c.Port)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid auth method %q, expected one of %s", c.Method, strings.Join(Methods, ", "))
+}
+
+// Masked renders the credentials without revealing secrets: the user of
+// basic auth, the key path of ssh and secret references are shown, tokens
+// and passwords are not.
+func (c AuthConfig) Masked() string {
+	if IsReference(c.Credentials) {
+		return c.Credentials
+	}
+	switch c.Method {
+	case "basic":
+		user, _, _ := strings.Cut(c.Credentials, ":")
+		return user + ":" + MaskSecret("")
+	case "ssh", "ssh-agent", GIT_CREDENTIAL_METHOD, "none":
+		return c.Credentials
+	default:
+		return MaskSecret(c.Credentials)
+	}
+}
+
+// MaskSecret keeps the first characters of long secrets, enough to tell
+// tokens apart, and hides the rest.
+func MaskSecret(secret string) string {
+	if len(secret) < 12 {
+		return "********"
+	}
+	return secret[:4] + "********"
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	assert.Equal(t, "github.com/org", NormalizeKey("github.com/org/"))
+	assert.Equal(t, "ssh://github.com/org/repo", NormalizeKey("ssh://git@github.com/org/repo.git"))
+	assert.Equal(t, "*.corp.example.com:8443", NormalizeKey("*.corp.example.com:8443"))
+	assert.Equal(t, "gitlab.com", NormalizeKey("gitlab.com"))
+}
+
+func TestAuthFile_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".zetten", DEFAULT_AUTH_FILE_NAME)
+
+	auths, err := LoadAuthFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, auths)
+
+	auths["github.com/org"] = AuthConfig{Method: "token", Credentials: "ghp_secret"}
+	require.NoError(t, SaveAuthFile(path, auths))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadAuthFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, auths, loaded)
+}
+
+func TestAuthConfig_Masked(t *testing.T) {
+	assert.Equal(t, "ghp_********", AuthConfig{Method: "token", Credentials: "ghp_abcdefghijklmnop"}.Masked())
+	assert.Equal(t, "********", AuthConfig{Method: "token", Credentials: "short"}.Masked())
+	assert.Equal(t, "me:********", AuthConfig{Method: "basic", Credentials: "me:secret"}.Masked())
+	assert.Equal(t, "~/.ssh/id_ed25519", AuthConfig{Method: "ssh", Credentials: "~/.ssh/id_ed25519"}.Masked())
+}
+
+func TestAuthConfig_Validate(t *testing.T) {
+	assert.NoError(t, AuthConfig{Method: "token", Credentials: "x"}.Validate())
+	assert.Error(t, AuthConfig{Method: "basic", Credentials: "nopassword"}.Validate())
+	assert.Error(t, AuthConfig{Method: "oauth"}.Validate())
+}
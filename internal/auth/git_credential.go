@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// GIT_CREDENTIAL_METHOD asks the credential helpers configured for git
+// itself, through `git credential fill`. The credentials of the entry, when
+// set, are the username to ask for.
+const GIT_CREDENTIAL_METHOD = "git-credential"
+
+// gitCredentialFill runs `git credential fill` for u and returns the filled
+// in username and password as basic auth.
+func gitCredentialFill(u *url.URL, username string) (*AuthConfig, error) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("git credential helpers only apply to http(s) urls, not %s", u.Redacted())
+	}
+	var input strings.Builder
+	fmt.Fprintf(&input, "protocol=%s\n", u.Scheme)
+	fmt.Fprintf(&input, "host=%s\n", u.Host)
+	if path := strings.TrimPrefix(u.Path, "/"); path != "" {
+		fmt.Fprintf(&input, "path=%s\n", path)
+	}
+	if username == "" && u.User != nil {
+		username = u.User.Username()
+	}
+	if username != "" {
+		fmt.Fprintf(&input, "username=%s\n", username)
+	}
+	input.WriteString("\n")
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git credential fill failed for %s: %v %s", u.Host, err, strings.TrimSpace(stderr.String()))
+	}
+
+	values := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if key, value, found := strings.Cut(line, "="); found {
+			values[key] = value
+		}
+	}
+	if values["password"] == "" {
+		return nil, fmt.Errorf("git credential fill returned no password for %s", u.Host)
+	}
+	return &AuthConfig{
+		Method:      "basic",
+		Credentials: values["username"] + ":" + values["password"],
+	}, nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Auth entries are keyed by patterns of the form
+//
+//	[scheme://]host[:port][/path]
+//
+// An entry applies to a repository url when:
+//   - its scheme, if any, is the scheme of the url (git@host:path urls are ssh)
+//   - its host, which may hold * and ? wildcards as in *.corp.example.com,
+//     matches the host of the url
+//   - its port, if any, is the port of the url
+//   - its path is a prefix of the url path, compared segment by segment and
+//     ignoring a trailing .git
+//
+// When several entries apply, the most specific wins: the longest path first,
+// then an exact host over a wildcard one, then the longest host pattern, then
+// an entry with a scheme, then one with a port.
+
+// Target is a repository url broken into the parts entries are matched on.
+type Target struct {
+	Scheme string
+	Host   string
+	Port   string
+	Path   []string
+	url    *url.URL
+}
+
+var scpLikeUrl = regexp.MustCompile(`^(?:([^@/]+)@)?([^:/]+):([^/].*)$`)
+
+// ParseTarget parses a repository url, scp-like ssh urls included.
+func ParseTarget(repoUrl string) (*Target, error) {
+	if !strings.Contains(repoUrl, "://") {
+		if m := scpLikeUrl.FindStringSubmatch(repoUrl); m != nil {
+			repoUrl = "ssh://" + m[2] + "/" + m[3]
+			if m[1] != "" {
+				repoUrl = "ssh://" + m[1] + "@" + m[2] + "/" + m[3]
+			}
+		}
+	}
+	u, err := url.Parse(repoUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repo URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("failed to parse repo URL: no host in %s", repoUrl)
+	}
+	return &Target{
+		Scheme: u.Scheme,
+		Host:   strings.ToLower(u.Hostname()),
+		Port:   u.Port(),
+		Path:   splitPath(u.Path),
+		url:    u,
+	}, nil
+}
+
+// URL returns a copy of the parsed url, scp-like urls being ssh:// ones.
+func (t *Target) URL() *url.URL {
+	u := *t.url
+	return &u
+}
+
+func splitPath(p string) []string {
+	p = strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// pattern is a parsed auth entry key.
+type pattern struct {
+	key    string
+	scheme string
+	host   string
+	port   string
+	path   []string
+}
+
+func parsePattern(key string) pattern {
+	p := pattern{key: key}
+	rest := key
+	if scheme, after, found := strings.Cut(rest, "://"); found {
+		p.scheme, rest = scheme, after
+	}
+	hostPort, pathPart, _ := strings.Cut(rest, "/")
+	if host, port, found := strings.Cut(hostPort, ":"); found {
+		p.host, p.port = host, port
+	} else {
+		p.host = hostPort
+	}
+	p.host = strings.ToLower(p.host)
+	p.path = splitPath(pathPart)
+	return p
+}
+
+func (p pattern) wildcard() bool {
+	return strings.ContainsAny(p.host, "*?[")
+}
+
+func (p pattern) matches(t *Target) bool {
+	if p.scheme != "" && p.scheme != t.Scheme {
+		return false
+	}
+	if p.port != "" && p.port != t.Port {
+		return false
+	}
+	if ok, err := path.Match(p.host, t.Host); err != nil || !ok {
+		return false
+	}
+	if len(p.path) > len(t.Path) {
+		return false
+	}
+	for i, segment := range p.path {
+		if segment != t.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific reports whether p wins over other when both match.
+func (p pattern) moreSpecific(other pattern) bool {
+	if len(p.path) != len(other.path) {
+		return len(p.path) > len(other.path)
+	}
+	if p.wildcard() != other.wildcard() {
+		return !p.wildcard()
+	}
+	if len(p.host) != len(other.host) {
+		return len(p.host) > len(other.host)
+	}
+	if (p.scheme != "") != (other.scheme != "") {
+		return p.scheme != ""
+	}
+	if (p.port != "") != (other.port != "") {
+		return p.port != ""
+	}
+	return p.key < other.key
+}
+
+// Matches returns the keys of the entries that apply to repoUrl, the one that
+// wins first.
+func (l *AuthConfigLoader) Matches(repoUrl string) ([]string, error) {
+	if err := l.Load(); err != nil {
+		return nil, err
+	}
+	target, err := ParseTarget(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+	var matched []pattern
+	for key := range l.Configs {
+		if p := parsePattern(key); p.matches(target) {
+			matched = append(matched, p)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].moreSpecific(matched[j])
+	})
+	keys := make([]string, len(matched))
+	for i, p := range matched {
+		keys[i] = p.key
+	}
+	return keys, nil
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatches(t *testing.T) {
+	loader := &AuthConfigLoader{Configs: AuthMap{
+		"github.com":                   {Method: "token", Credentials: "host"},
+		"github.com/org":               {Method: "token", Credentials: "org"},
+		"github.com/org/repo":          {Method: "token", Credentials: "repo"},
+		"ssh://github.com/org":         {Method: "ssh", Credentials: "~/.ssh/id"},
+		"*.corp.example.com":           {Method: "token", Credentials: "corp"},
+		"git.corp.example.com:8443":    {Method: "token", Credentials: "corp-port"},
+		"https://git.corp.example.com": {Method: "token", Credentials: "corp-https"},
+	}}
+
+	cases := map[string]string{
+		"https://github.com/org/repo.git":         "github.com/org/repo",
+		"https://github.com/org/repository.git":   "github.com/org",
+		"https://github.com/other/repo.git":       "github.com",
+		"git@github.com:org/other.git":            "ssh://github.com/org",
+		"ssh://git@github.com/org/repo.git":       "github.com/org/repo",
+		"https://a.corp.example.com/x/y.git":      "*.corp.example.com",
+		"https://git.corp.example.com/x/y.git":    "https://git.corp.example.com",
+		"ssh://git.corp.example.com:8443/x/y.git": "git.corp.example.com:8443",
+		"ssh://git.corp.example.com/x/y.git":      "*.corp.example.com",
+	}
+	for repoUrl, expected := range cases {
+		keys, err := loader.Matches(repoUrl)
+		require.NoError(t, err, repoUrl)
+		require.NotEmpty(t, keys, repoUrl)
+		assert.Equal(t, expected, keys[0], repoUrl)
+	}
+
+	keys, err := loader.Matches("https://gitlab.com/org/repo.git")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestWhich(t *testing.T) {
+	loader := &AuthConfigLoader{Configs: AuthMap{
+		"github.com/org": {Method: "token", Credentials: "org"},
+	}}
+
+	key, cfg, err := loader.Which("git@github.com:org/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/org", key)
+	assert.Equal(t, "org", cfg.Credentials)
+
+	_, _, err = loader.Which("https://gitlab.com/org/repo.git")
+	assert.ErrorIs(t, err, ErrNoAuthConfigFound)
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// netrcPath returns the .netrc file of the user, $NETRC when set.
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(os.Getenv("HOME"), name)
+}
+
+// netrcAuth looks host up in the netrc file at path and returns its login and
+// password as basic auth. The default entry applies to any host.
+func netrcAuth(path, host string) (*AuthConfig, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var (
+		machine, login, password string
+		found, inMacro           bool
+		lines                    = strings.Split(string(data), "\n")
+	)
+	// entries end where the next one starts
+	done := func() bool {
+		return found && password != ""
+	}
+	for _, line := range lines {
+		// macro definitions run until an empty line
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			next := func() string {
+				if i+1 < len(fields) {
+					i++
+					return fields[i]
+				}
+				return ""
+			}
+			switch fields[i] {
+			case "machine", "default":
+				if done() {
+					return basicAuth(login, password), true
+				}
+				machine, login, password = "", "", ""
+				if fields[i] == "machine" {
+					machine = next()
+				}
+				found = fields[i] == "default" || machine == host
+			case "login":
+				login = next()
+			case "password":
+				password = next()
+			case "account":
+				next()
+			case "macdef":
+				next()
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	if done() {
+		return basicAuth(login, password), true
+	}
+	return nil, false
+}
+
+func basicAuth(login, password string) *AuthConfig {
+	return &AuthConfig{Method: "basic", Credentials: login + ":" + password}
+}
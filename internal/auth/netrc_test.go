@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetrcAuth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".netrc")
+	require.NoError(t, os.WriteFile(path, []byte(`machine gitlab.com
+  login me
+  password gl-secret
+
+macdef init
+machine ignored.com login no password no
+
+machine github.com login octo password gh-secret
+default login anonymous password guest
+`), 0600))
+
+	cfg, found := netrcAuth(path, "github.com")
+	require.True(t, found)
+	assert.Equal(t, "octo:gh-secret", cfg.Credentials)
+
+	cfg, found = netrcAuth(path, "gitlab.com")
+	require.True(t, found)
+	assert.Equal(t, "me:gl-secret", cfg.Credentials)
+
+	cfg, found = netrcAuth(path, "ignored.com")
+	require.True(t, found)
+	assert.Equal(t, "anonymous:guest", cfg.Credentials)
+
+	_, found = netrcAuth(filepath.Join(t.TempDir(), "missing"), "github.com")
+	assert.False(t, found)
+}
+
+func TestFindAuth_NetrcFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".netrc")
+	require.NoError(t, os.WriteFile(path, []byte("machine example.com login me password secret\n"), 0600))
+	loader := &AuthConfigLoader{Configs: AuthMap{}, Netrc: path}
+
+	cfg, err := loader.FindAuth("https://example.com:8443/org/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, &AuthConfig{Method: "basic", Credentials: "me:secret"}, cfg)
+
+	_, err = loader.FindAuth("https://other.com/org/repo.git")
+	assert.ErrorIs(t, err, ErrNoAuthConfigFound)
+}
+
+func TestFindAuth_GitCredential(t *testing.T) {
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "credential.helper")
+	t.Setenv("GIT_CONFIG_VALUE_0", "!f() { echo username=helper; echo password=from-helper; }; f")
+	loader := &AuthConfigLoader{Configs: AuthMap{
+		"example.com/org": {Method: GIT_CREDENTIAL_METHOD},
+	}}
+
+	cfg, err := loader.FindAuth("https://example.com/org/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, &AuthConfig{Method: "basic", Credentials: "helper:from-helper"}, cfg)
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Credentials may reference a secret instead of holding it:
+//
+//	env:GITLAB_TOKEN         the value of an environment variable
+//	file:/run/secrets/token  the content of a file
+//	cmd:pass show gitlab     the output of a shell command
+//
+// References are resolved when the credentials are looked up, so the auth
+// file never stores the secret itself. The auth file of the current directory
+// may come with a cloned repository, so its references, which could send any
+// secret of the environment to the host of the entry, are only resolved when
+// AUTH_TRUST_LOCAL_ENV is set.
+var secretSources = map[string]func(ref string) (string, error){
+	"env":  envSecret,
+	"file": fileSecret,
+	"cmd":  cmdSecret,
+}
+
+// AUTH_TRUST_LOCAL_ENV opts in to resolving the secret references of the
+// local auth file.
+const AUTH_TRUST_LOCAL_ENV = "ZETTEN_AUTH_TRUST_LOCAL"
+
+// IsReference reports whether credentials reference a secret.
+func IsReference(credentials string) bool {
+	scheme, _, found := strings.Cut(credentials, ":")
+	_, known := secretSources[scheme]
+	return found && known
+}
+
+// ResolveCredentials returns the secret referenced by credentials, or the
+// credentials themselves when they are not a reference. Surrounding
+// whitespace, such as the trailing newline of files and commands, is trimmed.
+func ResolveCredentials(credentials string) (string, error) {
+	return resolveCredentials(credentials, true)
+}
+
+func resolveCredentials(credentials string, trusted bool) (string, error) {
+	if !IsReference(credentials) {
+		return credentials, nil
+	}
+	scheme, ref, _ := strings.Cut(credentials, ":")
+	if !trusted {
+		return "", fmt.Errorf("%w: %s: references of the local auth file are ignored, move the entry to %s or set %s=1",
+			ErrUnresolvedSecret, credentials, ScopePath(GlobalScope, ""), AUTH_TRUST_LOCAL_ENV)
+	}
+	secret, err := secretSources[scheme](strings.TrimSpace(ref))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrUnresolvedSecret, credentials, err)
+	}
+	return strings.TrimSpace(secret), nil
+}
+
+func envSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func fileSecret(path string) (string, error) {
+	data, err := os.ReadFile(os.ExpandEnv(path))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func cmdSecret(command string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Resolve returns a copy of the config with its credentials and passphrase
+// resolved.
+func (c AuthConfig) Resolve() (*AuthConfig, error) {
+	return c.resolve(true)
+}
+
+// resolve is Resolve for a config read from an auth file, which may only
+// reference secrets and relax host key verification when trusted.
+func (c AuthConfig) resolve(trusted bool) (*AuthConfig, error) {
+	if !trusted && (c.InsecureIgnoreHostKey || c.KnownHosts != "") {
+		return nil, fmt.Errorf("%w: known_hosts and insecure_ignore_host_key of the local auth file are refused, move the entry to %s or set %s=1",
+			ErrUntrustedHostKey, ScopePath(GlobalScope, ""), AUTH_TRUST_LOCAL_ENV)
+	}
+	credentials, err := resolveCredentials(c.Credentials, trusted)
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := resolveCredentials(c.Passphrase, trusted)
+	if err != nil {
+		return nil, err
+	}
+	c.Credentials = credentials
+	c.Passphrase = passphrase
+	return &c, nil
+}
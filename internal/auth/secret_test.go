@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCredentials(t *testing.T) {
+	t.Setenv("ZETTEN_TEST_TOKEN", "from-env")
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0600))
+
+	cases := map[string]string{
+		"plain-token":             "plain-token",
+		"user:password":           "user:password",
+		"env:ZETTEN_TEST_TOKEN":   "from-env",
+		"file:" + path:            "from-file",
+		"cmd:printf 'from-cmd\n'": "from-cmd",
+	}
+	for credentials, expected := range cases {
+		secret, err := ResolveCredentials(credentials)
+		require.NoError(t, err, credentials)
+		assert.Equal(t, expected, secret, credentials)
+	}
+}
+
+func TestResolveCredentials_Errors(t *testing.T) {
+	for _, credentials := range []string{
+		"env:ZETTEN_TEST_UNSET_TOKEN",
+		"file:/does/not/exist",
+		"cmd:exit 1",
+	} {
+		_, err := ResolveCredentials(credentials)
+		assert.True(t, errors.Is(err, ErrUnresolvedSecret), credentials)
+	}
+}
+
+func TestFindAuth_ResolvesLazily(t *testing.T) {
+	loader := &AuthConfigLoader{Configs: AuthMap{
+		"github.com/org": {Method: "token", Credentials: "env:ZETTEN_TEST_TOKEN"},
+	}}
+
+	t.Setenv("ZETTEN_TEST_TOKEN", "secret")
+	cfg, err := loader.FindAuth("https://github.com/org/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", cfg.Credentials)
+	assert.Equal(t, "env:ZETTEN_TEST_TOKEN", loader.Configs["github.com/org"].Credentials)
+}
+
+func TestFindAuth_LocalFileHostKeys(t *testing.T) {
+	local := filepath.Join(t.TempDir(), DEFAULT_AUTH_FILE_NAME)
+	require.NoError(t, SaveAuthFile(local, AuthMap{
+		"ssh://github.com":   {Method: "ssh-agent", InsecureIgnoreHostKey: true},
+		"ssh://gitlab.com":   {Method: "ssh-agent", KnownHosts: "known_hosts"},
+		"ssh://git.corp.com": {Method: "ssh-agent"},
+	}))
+	loader := &AuthConfigLoader{paths: []string{local}, local: local}
+
+	for _, url := range []string{"ssh://git@github.com/org/repo", "ssh://git@gitlab.com/org/repo"} {
+		_, err := loader.FindAuth(url)
+		assert.ErrorIs(t, err, ErrUntrustedHostKey, url)
+	}
+	_, err := loader.FindAuth("ssh://git@git.corp.com/org/repo")
+	assert.NoError(t, err)
+
+	t.Setenv(AUTH_TRUST_LOCAL_ENV, "1")
+	cfg, err := loader.FindAuth("ssh://git@github.com/org/repo")
+	require.NoError(t, err)
+	assert.True(t, cfg.InsecureIgnoreHostKey)
+}
+
+func TestFindAuth_LocalFileReferences(t *testing.T) {
+	t.Setenv("ZETTEN_TEST_TOKEN", "from-env")
+	local := filepath.Join(t.TempDir(), DEFAULT_AUTH_FILE_NAME)
+	require.NoError(t, SaveAuthFile(local, AuthMap{
+		"github.com/env": {Method: "token", Credentials: "env:ZETTEN_TEST_TOKEN"},
+		"github.com/cmd": {Method: "token", Credentials: "cmd:printf from-cmd"},
+	}))
+	loader := &AuthConfigLoader{paths: []string{local}, local: local}
+
+	for _, url := range []string{"https://github.com/env/repo", "https://github.com/cmd/repo"} {
+		_, err := loader.FindAuth(url)
+		assert.ErrorIs(t, err, ErrUnresolvedSecret, url)
+	}
+
+	t.Setenv(AUTH_TRUST_LOCAL_ENV, "1")
+	cfg, err := loader.FindAuth("https://github.com/env/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.Credentials)
+	cfg, err = loader.FindAuth("https://github.com/cmd/repo")
+	require.NoError(t, err)
+	assert.Equal(t, "from-cmd", cfg.Credentials)
+}
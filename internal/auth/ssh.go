@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const DEFAULT_SSH_USER = "git"
+
+// PromptKeyPassphrase asks for the passphrase of an encrypted ssh key that
+// has none configured. The CLI replaces it with an interactive prompt.
+var PromptKeyPassphrase = func(keyPath string) (string, error) {
+	return "", fmt.Errorf("%s is encrypted, set passphrase in the auth config", keyPath)
+}
+
+// sshAuth authenticates with the ssh agent for the ssh-agent method, or with
+// the private key at the credentials path, asking for its passphrase when the
+// key is encrypted and none is configured.
+func sshAuth(cfg *AuthConfig) (transport.AuthMethod, error) {
+	user := util.Or(cfg.User, DEFAULT_SSH_USER)
+	hostKeyCallback, err := HostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Method == "ssh-agent" {
+		agent, err := ssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reach the SSH agent: %w", err)
+		}
+		agent.HostKeyCallback = hostKeyCallback
+		return agent, nil
+	}
+
+	keyPath := expandHome(cfg.Credentials)
+	pem, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	passphrase := cfg.Passphrase
+	var missing *gossh.PassphraseMissingError
+	if _, err := gossh.ParsePrivateKey(pem); errors.As(err, &missing) && passphrase == "" {
+		passphrase, err = PromptKeyPassphrase(keyPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	publicKeys, err := ssh.NewPublicKeys(user, pem, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key: %w", err)
+	}
+	publicKeys.HostKeyCallback = hostKeyCallback
+	return publicKeys, nil
+}
+
+// HostKeyCallback verifies host keys against the known_hosts file of the
+// config, or the default ones ($SSH_KNOWN_HOSTS, ~/.ssh/known_hosts,
+// /etc/ssh/ssh_known_hosts). Verification is only skipped when the config
+// opts out of it explicitly.
+func HostKeyCallback(cfg *AuthConfig) (gossh.HostKeyCallback, error) {
+	if cfg.InsecureIgnoreHostKey {
+		fmt.Println("⚠️ SSH host key verification is disabled")
+		return gossh.InsecureIgnoreHostKey(), nil
+	}
+	var files []string
+	if cfg.KnownHosts != "" {
+		files = append(files, expandHome(cfg.KnownHosts))
+	}
+	callback, err := ssh.NewKnownHostsCallback(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts, set known_hosts or insecure_ignore_host_key in the auth config: %w", err)
+	}
+	return callback, nil
+}
+
+// SSHUrl applies the user and port of an ssh auth config to an ssh url,
+// scp-like urls (git@host:org/repo) being turned into ssh:// ones when a port
+// is set. Users and ports already in the url are kept, and other urls are
+// returned unchanged.
+func SSHUrl(repoUrl string, cfg *AuthConfig) string {
+	if cfg == nil || (cfg.Method != "ssh" && cfg.Method != "ssh-agent") {
+		return repoUrl
+	}
+	target, err := ParseTarget(repoUrl)
+	if err != nil || target.Scheme != "ssh" {
+		return repoUrl
+	}
+	user := cfg.User
+	if !strings.Contains(repoUrl, "://") {
+		if cfg.Port == 0 {
+			return repoUrl
+		}
+		user = util.Or(user, DEFAULT_SSH_USER)
+	}
+	u := target.URL()
+	if u.User == nil && user != "" {
+		u.User = url.User(user)
+	}
+	if u.Port() == "" && cfg.Port != 0 {
+		u.Host = u.Hostname() + ":" + strconv.Itoa(cfg.Port)
+	}
+	return u.String()
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return os.ExpandEnv(path)
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHUrl(t *testing.T) {
+	cfg := &AuthConfig{Method: "ssh", User: "deploy", Port: 2222}
+
+	assert.Equal(t, "ssh://deploy@git.corp.com:2222/org/repo.git", SSHUrl("ssh://git.corp.com/org/repo.git", cfg))
+	assert.Equal(t, "ssh://git@git.corp.com:22/org/repo.git", SSHUrl("ssh://git@git.corp.com:22/org/repo.git", cfg))
+	assert.Equal(t, "ssh://git@git.corp.com:2222/org/repo.git", SSHUrl("git@git.corp.com:org/repo.git", cfg))
+	assert.Equal(t, "https://git.corp.com/org/repo.git", SSHUrl("https://git.corp.com/org/repo.git", cfg))
+	assert.Equal(t, "git@git.corp.com:org/repo.git", SSHUrl("git@git.corp.com:org/repo.git", &AuthConfig{Method: "ssh"}))
+}
+
+func TestHostKeyCallback_Strict(t *testing.T) {
+	_, err := HostKeyCallback(&AuthConfig{Method: "ssh", KnownHosts: filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+
+	callback, err := HostKeyCallback(&AuthConfig{Method: "ssh", InsecureIgnoreHostKey: true})
+	assert.NoError(t, err)
+	assert.NotNil(t, callback)
+}
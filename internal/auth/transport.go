@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// ResolveAuth finds the auth entry of repoUrl and returns the url to use,
+// with its ssh settings applied, and the transport auth. Urls without an
+// entry or a host are returned as is with a nil auth.
+func ResolveAuth(repoUrl string) (string, transport.AuthMethod, error) {
+	// local repositories, file:// urls and paths, and other urls without a
+	// host have nothing to authenticate with
+	if util.IsLocalPath(repoUrl) {
+		return repoUrl, nil, nil
+	}
+	if _, err := ParseTarget(repoUrl); err != nil {
+		return repoUrl, nil, nil
+	}
+	cfg, err := Loader.FindAuth(repoUrl)
+	if err == ErrNoAuthConfigFound {
+		return repoUrl, nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load auth for url %s: %w", repoUrl, err)
+	}
+	method, err := AuthMethod(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	return SSHUrl(repoUrl, cfg), method, nil
+}
+
+// AuthMethod builds the transport auth of an auth config, nil for "none".
+// Its ssh settings are applied to the url by SSHUrl.
+func AuthMethod(cfg *AuthConfig) (transport.AuthMethod, error) {
+	switch cfg.Method {
+	case "token":
+		return &http.BasicAuth{
+			Username: "git",
+			Password: cfg.Credentials,
+		}, nil
+	case "basic":
+		// passwords may contain colons, usernames may not
+		username, password, ok := strings.Cut(cfg.Credentials, ":")
+		if !ok {
+			return nil, fmt.Errorf("basic auth credentials must be in format 'username:password'")
+		}
+		return &http.BasicAuth{
+			Username: username,
+			Password: password,
+		}, nil
+	case "ssh", "ssh-agent":
+		return sshAuth(cfg)
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid auth method: %s", cfg.Method)
+	}
+}
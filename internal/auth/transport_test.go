@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMethod_BasicPasswordWithColon(t *testing.T) {
+	method, err := AuthMethod(&AuthConfig{Method: "basic", Credentials: "alice:pa:ss"})
+	require.NoError(t, err)
+	assert.Equal(t, &http.BasicAuth{Username: "alice", Password: "pa:ss"}, method)
+
+	_, err = AuthMethod(&AuthConfig{Method: "basic", Credentials: "alice"})
+	assert.Error(t, err)
+}
+
+func TestResolveAuth(t *testing.T) {
+	previous := Loader
+	t.Cleanup(func() { Loader = previous })
+	Loader = &AuthConfigLoader{Configs: AuthMap{
+		"https://git.corp.com/org": {Method: "token", Credentials: "secret"},
+	}}
+
+	url, method, err := ResolveAuth("https://git.corp.com/org/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, "https://git.corp.com/org/repo.git", url)
+	assert.Equal(t, &http.BasicAuth{Username: "git", Password: "secret"}, method)
+
+	url, method, err = ResolveAuth("https://example.com/public/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/public/repo.git", url)
+	assert.Nil(t, method)
+
+	for _, local := range []string{"/srv/git/ui", "file:///srv/git/ui", "../ui"} {
+		url, method, err = ResolveAuth(local)
+		require.NoError(t, err, local)
+		assert.Equal(t, local, url)
+		assert.Nil(t, method)
+	}
+}
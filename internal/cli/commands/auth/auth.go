@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/auth"
+	"github.com/core-stack/zetten-cli/internal/cli/git_util"
+	"github.com/core-stack/zetten-cli/internal/cli/prompt"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+type AuthCommand struct {
+	Add    AddCommand    `cmd:"" help:"Add or replace the credentials of a host/path."`
+	List   ListCommand   `cmd:"" help:"List configured credentials with secrets masked."`
+	Remove RemoveCommand `cmd:"" help:"Remove the credentials of a host/path."`
+	Test   TestCommand   `cmd:"" help:"Check that the credentials of a host/path can reach a repository."`
+	Which  WhichCommand  `cmd:"" help:"Show which credentials apply to a url and why."`
+
+	Encrypt EncryptCommand `cmd:"" help:"Encrypt an auth file with a passphrase or a key file."`
+	Decrypt DecryptCommand `cmd:"" help:"Store an encrypted auth file in plain text again."`
+}
+
+type scopeFlags struct {
+	Global bool `help:"Use the global auth file under ~/.zetten instead of the local one" short:"g" long:"global"`
+}
+
+func (f scopeFlags) path() string {
+	if f.Global {
+		return auth.ScopePath(auth.GlobalScope, auth.DEFAULT_AUTH_FILE_NAME)
+	}
+	return auth.ScopePath(auth.LocalScope, auth.DEFAULT_AUTH_FILE_NAME)
+}
+
+type AddCommand struct {
+	scopeFlags
+	Target      string `arg:"" help:"The host/path the credentials apply to (e.g. github.com/org)"`
+	Method      string `help:"The auth method: token, basic, ssh, ssh-agent, git-credential or none" short:"m" long:"method" enum:",token,basic,ssh,ssh-agent,git-credential,none" default:""`
+	Credentials string `help:"The token, username:password or ssh key path, or a env:, file: or cmd: reference to it; prompted for when omitted" short:"c" long:"credentials"`
+
+	User                  string `help:"The SSH user, git by default" long:"user"`
+	Port                  int    `help:"The SSH port" long:"port"`
+	Passphrase            string `help:"A env:, file: or cmd: reference to the passphrase of the SSH key, asked for when omitted" long:"passphrase"`
+	KnownHosts            string `help:"The known_hosts file to verify SSH host keys against" long:"known-hosts"`
+	InsecureIgnoreHostKey bool   `help:"Do not verify SSH host keys" long:"insecure-ignore-host-key"`
+}
+
+func (c *AddCommand) Run() error {
+	var err error
+	if c.Method == "" {
+		c.Method, err = prompt.PromptSelect("🔐 Method", auth.Methods, false)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Credentials == "" && c.Method != "none" && c.Method != "ssh-agent" && c.Method != auth.GIT_CREDENTIAL_METHOD {
+		opts := []prompt.CreatePromptInputOpts{}
+		if c.Method != "ssh" {
+			opts = append(opts, prompt.WithMask())
+		}
+		c.Credentials, err = prompt.PromptInput(credentialsLabel(c.Method), opts...)
+		if err != nil {
+			return err
+		}
+	}
+	cfg := auth.AuthConfig{
+		Method:                c.Method,
+		Credentials:           c.Credentials,
+		User:                  c.User,
+		Port:                  c.Port,
+		Passphrase:            c.Passphrase,
+		KnownHosts:            c.KnownHosts,
+		InsecureIgnoreHostKey: c.InsecureIgnoreHostKey,
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	path := c.path()
+	auths, err := auth.LoadAuthFile(path)
+	if err != nil {
+		return err
+	}
+	key := auth.NormalizeKey(c.Target)
+	auths[key] = cfg
+	if err := auth.SaveAuthFile(path, auths); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Saved %s credentials for %s in %s\n", cfg.Method, key, path)
+	return nil
+}
+
+func credentialsLabel(method string) string {
+	switch method {
+	case "basic":
+		return "Username:password"
+	case "ssh":
+		return "SSH key path"
+	default:
+		return "Token"
+	}
+}
+
+type ListCommand struct {
+	scopeFlags
+	All bool `help:"List both the local and the global auth files" short:"a" long:"all"`
+}
+
+func (c *ListCommand) Run() error {
+	paths := []string{c.path()}
+	if c.All {
+		paths = []string{
+			auth.ScopePath(auth.LocalScope, auth.DEFAULT_AUTH_FILE_NAME),
+			auth.ScopePath(auth.GlobalScope, auth.DEFAULT_AUTH_FILE_NAME),
+		}
+	}
+	for _, path := range paths {
+		auths, err := auth.LoadAuthFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📄 %s\n", path)
+		if len(auths) == 0 {
+			fmt.Println("    no credentials")
+			continue
+		}
+		for _, key := range auths.Keys() {
+			cfg := auths[key]
+			fmt.Printf("    %s  %s  %s\n", key, cfg.Method, cfg.Masked())
+		}
+	}
+	return nil
+}
+
+type RemoveCommand struct {
+	scopeFlags
+	Target string `arg:"" help:"The host/path to remove the credentials of"`
+}
+
+func (c *RemoveCommand) Run() error {
+	path := c.path()
+	auths, err := auth.LoadAuthFile(path)
+	if err != nil {
+		return err
+	}
+	key := auth.NormalizeKey(c.Target)
+	if _, ok := auths[key]; !ok {
+		return fmt.Errorf("no credentials for %s in %s", key, path)
+	}
+	delete(auths, key)
+	if err := auth.SaveAuthFile(path, auths); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️ Removed credentials for %s from %s\n", key, path)
+	return nil
+}
+
+type TestCommand struct {
+	Target string `arg:"" help:"The url or host/path of a repository (e.g. github.com/org/repo)"`
+	Url    string `help:"The repository url to test, when it cannot be derived from the host/path" short:"u" long:"url"`
+}
+
+func (c *TestCommand) Run() error {
+	url := util.Or(c.Url, c.Target)
+	cfg, err := auth.Loader.FindAuth(url)
+	if err != nil && !isUrl(url) {
+		// a host/path is tried over https, then over ssh
+		url = "https://" + auth.NormalizeKey(c.Target) + ".git"
+		if cfg, err = auth.Loader.FindAuth(url); err != nil {
+			url = sshRepoUrl(auth.NormalizeKey(c.Target))
+			cfg, err = auth.Loader.FindAuth(url)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Target, err)
+	}
+	method, err := auth.AuthMethod(cfg)
+	if err != nil {
+		return err
+	}
+	if (cfg.Method == "ssh" || cfg.Method == "ssh-agent") && strings.HasPrefix(url, "https://") {
+		url = sshRepoUrl(strings.TrimSuffix(strings.TrimPrefix(url, "https://"), ".git"))
+	}
+	url = auth.SSHUrl(url, cfg)
+
+	fmt.Printf("🔌 Testing %s credentials against %s\n", cfg.Method, url)
+	if _, err := git_util.RemoteRefExists(url, "HEAD", method); err != nil {
+		return fmt.Errorf("❌ %s: %w", url, err)
+	}
+	fmt.Printf("✅ %s is reachable\n", url)
+	return nil
+}
+
+type WhichCommand struct {
+	Url string `arg:"" help:"The repository url to look credentials up for"`
+}
+
+func (c *WhichCommand) Run() error {
+	keys, err := auth.Loader.Matches(c.Url)
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		cfg := auth.Loader.Configs[key]
+		marker := "  "
+		if i == 0 {
+			marker = "👉"
+		}
+		fmt.Printf("%s %s  %s  %s  (%s)\n", marker, key, cfg.Method, cfg.Masked(), auth.Loader.Sources[key])
+	}
+	if len(keys) > 0 {
+		return nil
+	}
+	key, cfg, err := auth.Loader.Which(c.Url)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Url, err)
+	}
+	fmt.Printf("👉 %s  %s  %s  (%s)\n", key, cfg.Method, cfg.Masked(), auth.Loader.Netrc)
+	return nil
+}
+
+func isUrl(target string) bool {
+	_, err := auth.ParseTarget(target)
+	return err == nil && (strings.Contains(target, "://") || strings.Contains(target, "@"))
+}
+
+// sshRepoUrl derives the ssh url of a repository from its host/path.
+func sshRepoUrl(key string) string {
+	host, path, _ := strings.Cut(key, "/")
+	return fmt.Sprintf("ssh://git@%s/%s.git", host, path)
+}
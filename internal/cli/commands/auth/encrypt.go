@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/core-stack/zetten-cli/internal/auth"
+	"github.com/core-stack/zetten-cli/internal/cli/prompt"
+)
+
+func init() {
+	auth.PromptPassphrase = func(path string) (string, error) {
+		return prompt.PromptInput(fmt.Sprintf("🔑 Passphrase for %s", path), prompt.WithMask())
+	}
+	auth.PromptKeyPassphrase = func(keyPath string) (string, error) {
+		return prompt.PromptInput(fmt.Sprintf("🔑 Passphrase for %s", keyPath), prompt.WithMask())
+	}
+	auth.PromptNewPassphrase = func(path string) (string, error) {
+		passphrase, err := prompt.PromptInput(fmt.Sprintf("🔑 New passphrase for %s", path), prompt.WithMask())
+		if err != nil {
+			return "", err
+		}
+		again, err := prompt.PromptInput("🔑 Repeat the passphrase", prompt.WithMask())
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("passphrases do not match")
+		}
+		return passphrase, nil
+	}
+}
+
+type EncryptCommand struct {
+	scopeFlags
+	KeyFile bool `help:"Encrypt with a random key stored in ~/.zetten/auth.key instead of a passphrase" long:"key-file"`
+}
+
+func (c *EncryptCommand) Run() error {
+	path := c.path()
+	kdf := auth.PassphraseKdf
+	if c.KeyFile {
+		kdf = auth.KeyFileKdf
+		if err := auth.CreateKeyFile(); err != nil {
+			return err
+		}
+	}
+	if err := auth.EncryptAuthFile(path, kdf); err != nil {
+		return err
+	}
+	fmt.Printf("🔒 Encrypted %s (%s)\n", path, kdf)
+	return nil
+}
+
+type DecryptCommand struct {
+	scopeFlags
+}
+
+func (c *DecryptCommand) Run() error {
+	path := c.path()
+	if auth.EncryptionOf(path) == "" {
+		return fmt.Errorf("%s is not encrypted", path)
+	}
+	if err := auth.EncryptAuthFile(path, ""); err != nil {
+		return err
+	}
+	fmt.Printf("🔓 Decrypted %s\n", path)
+	return nil
+}
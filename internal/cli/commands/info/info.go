@@ -0,0 +1,87 @@
+package info
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+type InfoCommand struct {
+	Package string `arg:"" help:"The URL of the package, or its name in a registry"`
+
+	config *root.RootConfig
+}
+
+func (c *InfoCommand) BeforeApply() error {
+	config, err := root.LoadRootConfig()
+	if err != nil {
+		return err
+	}
+	c.config = config
+	return nil
+}
+
+func (c *InfoCommand) Run() error {
+	info, err := c.config.Info(c.Package)
+	if err != nil {
+		return err
+	}
+
+	name := info.Url
+	if info.Entry != nil {
+		name = info.Entry.Name
+	}
+	fmt.Printf("📦 %s\n", name)
+	fmt.Printf("    url: %s\n", info.Url)
+	if info.Entry != nil {
+		printField("description", info.Entry.Description)
+		printField("tags", strings.Join(info.Entry.Tags, ", "))
+	}
+	if m := info.Manifest; m != nil {
+		printField("repository", m.Repository)
+		if info.Entry == nil || info.Entry.Description == "" {
+			printField("description", m.Description)
+		}
+		printField("license", m.License)
+		if len(m.Dependencies) > 0 {
+			fmt.Println("    dependencies:")
+			urls := util.MapKeys(m.Dependencies)
+			sort.Strings(urls)
+			for _, url := range urls {
+				fmt.Printf("      %s@%s\n", url, m.Dependencies[url])
+			}
+		}
+	}
+	printField("default branch", info.DefaultBranch)
+	printField("latest", info.Latest)
+
+	if len(info.Tags) > 0 {
+		fmt.Println("🏷️ Versions")
+		for _, tag := range info.Tags {
+			if tag.Date.IsZero() {
+				fmt.Printf("    %s\n", tag.Name)
+			} else {
+				fmt.Printf("    %-16s %s\n", tag.Name, tag.Date.Format("2006-01-02"))
+			}
+		}
+	}
+
+	if len(info.Projects) > 0 {
+		fmt.Println("📁 Used by")
+		dirs := util.MapKeys(info.Projects)
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			fmt.Printf("    %s (%s)\n", dir, info.Projects[dir])
+		}
+	}
+	return nil
+}
+
+func printField(name, value string) {
+	if value != "" {
+		fmt.Printf("    %s: %s\n", name, value)
+	}
+}
@@ -1,14 +1,24 @@
 package install
 
 import (
-	"github.com/core-stack/zetten-cli/internal/cli/git_util"
+	"fmt"
+	"slices"
+
 	"github.com/core-stack/zetten-cli/internal/cli/prompt"
 	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
 )
 
 type InstallCommand struct {
+	Package string `arg:"" optional:"" help:"A package name from the registry, optionally with a version constraint (e.g. ui-kit@^2)"`
+
 	Url string `help:"The URL of the package to install" short:"u" long:"url"`
-	Tag string `help:"The tag/version to install" short:"t" long:"tag"`
+	Tag string `help:"The tag/version to install, or the sha256:<hex> checksum of an archive" short:"t" long:"tag"`
+
+	Force     bool   `help:"Back up local changes of the installed package and overwrite them" short:"f" xor:"local"`
+	KeepLocal bool   `help:"Merge local changes of the installed package into the new version" short:"k" xor:"local"`
+	Link      string `help:"How to install the package: copy, symlink, hardlink or reflink" enum:",copy,symlink,hardlink,reflink" default:""`
 
 	config *project.ProjectConfig
 }
@@ -24,19 +34,70 @@ func (c *InstallCommand) BeforeApply() error {
 
 func (c *InstallCommand) Run() error {
 	var err error
+	if c.Package != "" {
+		if err := c.resolvePackage(); err != nil {
+			return err
+		}
+	}
 	if c.Url == "" {
 		c.Url, err = prompt.PromptInput("Package URL")
 		if err != nil {
 			return err
 		}
 	}
-	repo, err := c.config.Root.OpenOrClonePackage(c.Url)
+	opts := []project.InstallOpt{
+		project.WithLocalChanges(project.LocalChangesPolicyFromFlags(c.Force, c.KeepLocal)),
+		project.WithLink(project.LinkMode(c.Link)),
+	}
+	// archives are pinned to their checksum, not to a tag
+	if util.IsArchive(c.Url) {
+		return c.config.Install(c.Url, c.Tag, opts...)
+	}
+	// local packages follow their directory unless a tag is asked for
+	if util.IsLocalPath(c.Url) && (c.Tag == "" || c.Tag == project.LOCAL_VERSION) {
+		return c.config.Install(c.Url, project.LOCAL_VERSION, opts...)
+	}
+	tag, err := c.loadTag()
 	if err != nil {
 		return err
 	}
-	tag, err := git_util.LoadTag(repo, c.Tag)
+	return c.config.Install(c.Url, tag, opts...)
+}
+
+// resolvePackage looks the package argument up in the registries of the root
+// config and fills in its url and version.
+func (c *InstallCommand) resolvePackage() error {
+	if c.Url != "" {
+		return fmt.Errorf("a package name and --url cannot be used together")
+	}
+	rootConfig, err := root.LoadRootConfig()
 	if err != nil {
 		return err
 	}
-	return c.config.Install(c.Url, tag)
+	url, version, err := rootConfig.ResolvePackage(c.Package)
+	if err != nil {
+		return err
+	}
+	c.Url = url
+	if version != "" {
+		c.Tag = version
+	}
+	fmt.Printf("🔎 %s resolved to %s %s\n", c.Package, url, version)
+	return nil
+}
+
+// loadTag returns the requested tag when the package has it, otherwise lets
+// the user pick one of its versions.
+func (c *InstallCommand) loadTag() (string, error) {
+	versions, err := c.config.Root.Versions(c.config.SourceURL(c.Url))
+	if err != nil {
+		return "", err
+	}
+	if c.Tag != "" {
+		if slices.Contains(versions, c.Tag) {
+			return c.Tag, nil
+		}
+		fmt.Println("tag not found, selecting tag...")
+	}
+	return prompt.PromptSelect("📝 Tag", versions, true)
 }
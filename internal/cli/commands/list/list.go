@@ -0,0 +1,83 @@
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+type ListCommand struct {
+	Json bool `help:"Print the dependencies as JSON" long:"json"`
+	Tree bool `help:"Also list the dependencies declared by each package manifest" long:"tree"`
+
+	config *project.ProjectConfig
+}
+
+func (c *ListCommand) BeforeApply() error {
+	config, err := project.LoadProjectConfig("zetten.yml")
+	if err != nil {
+		return err
+	}
+	c.config = config
+	return nil
+}
+
+func (c *ListCommand) Run() error {
+	packages, err := c.config.List(c.Tree)
+	if err != nil {
+		return err
+	}
+	if c.Json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if packages == nil {
+			packages = []*project.InstalledPackage{}
+		}
+		return encoder.Encode(packages)
+	}
+	if len(packages) == 0 {
+		fmt.Println("No dependencies installed")
+		return nil
+	}
+	for _, installed := range packages {
+		printPackage(installed, 0)
+	}
+	return nil
+}
+
+func printPackage(installed *project.InstalledPackage, depth int) {
+	indent := strings.Repeat("    ", depth)
+	icon := "✅"
+	switch {
+	case !installed.Present:
+		icon = "⚠️"
+	case installed.Modified:
+		icon = "📝"
+	}
+
+	fmt.Printf("%s%s %s@%s", indent, icon, installed.Url, installed.Version)
+	if installed.Revision != "" && installed.Revision != installed.Version {
+		fmt.Printf(" (%.12s)", installed.Revision)
+	}
+	fmt.Println()
+	if installed.Present {
+		details := []string{installed.Dir, util.FormatSize(installed.Size)}
+		if installed.Link != "" && installed.Link != string(project.LinkCopy) {
+			details = append(details, installed.Link)
+		}
+		if installed.Modified {
+			details = append(details, "local changes")
+		}
+		fmt.Printf("%s    %s\n", indent, strings.Join(details, ", "))
+	} else if depth == 0 {
+		fmt.Printf("%s    not installed, run `zetten sync`\n", indent)
+	}
+
+	for _, dep := range installed.Dependencies {
+		printPackage(dep, depth+1)
+	}
+}
@@ -0,0 +1,46 @@
+package patch
+
+import (
+	"fmt"
+
+	"github.com/core-stack/zetten-cli/internal/cli/prompt"
+	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+type PatchCommand struct {
+	Url string `arg:"" optional:"" help:"The URL of the package to capture local changes from"`
+
+	config *project.ProjectConfig
+}
+
+func (c *PatchCommand) BeforeApply() error {
+	config, err := project.LoadProjectConfig("zetten.yml")
+	if err != nil {
+		return err
+	}
+	c.config = config
+	return nil
+}
+
+func (c *PatchCommand) Run() error {
+	var err error
+	if c.Url == "" {
+		keys := util.MapKeys[map[string]string](c.config.Dependencies)
+		c.Url, err = prompt.PromptSelect("Select a package to patch", keys, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	path, err := c.config.CreatePatch(c.Url)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		fmt.Printf("✅ %s has no local changes, no patch needed\n", c.Url)
+		return nil
+	}
+	fmt.Printf("🩹 Local changes of %s saved to %s\n", c.Url, path)
+	return nil
+}
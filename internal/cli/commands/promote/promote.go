@@ -1,14 +1,21 @@
 package promote
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/core-stack/zetten-cli/internal/cli/prompt"
 	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/core-stack/zetten-cli/internal/core/root"
 	"github.com/core-stack/zetten-cli/internal/util"
 )
 
 type PromoteCommand struct {
-	Url string `help:"The URL of the package to install" short:"u" long:"url"`
-	Tag string `help:"The tag/version to install" short:"t" long:"tag"`
+	Url      string `help:"The URL of the package to install" short:"u" long:"url"`
+	Tag      string `help:"The tag/version to install" short:"t" long:"tag"`
+	Message  string `help:"Summary of the changes, preferably as a conventional commit (e.g. 'feat: add button')" short:"m" long:"message"`
+	Strategy string `help:"How to reconcile local edits with newer upstream versions (merge or rebase)" short:"s" long:"strategy" enum:",merge,rebase" default:""`
+	Push     bool   `help:"Push the new tag to the package source" long:"push"`
 
 	config *project.ProjectConfig
 }
@@ -39,5 +46,18 @@ func (c *PromoteCommand) Run() error {
 		c.Tag = tag
 	}
 
-	return c.config.Promote(c.Url, c.Tag)
+	opts := []root.PromoteOpt{root.WithMessage(c.Message)}
+	if c.Push {
+		opts = append(opts, root.WithPush())
+	}
+	err = c.config.Promote(c.Url, c.Tag, append(opts, root.WithStrategy(root.PromoteStrategy(c.Strategy)))...)
+	if errors.Is(err, root.ErrUpstreamChanged) && c.Strategy == "" {
+		fmt.Printf("⚠️ %v\n", err)
+		strategy, err := prompt.PromptSelect("Apply local changes on top of the latest version with", []string{string(root.RebaseStrategy), string(root.MergeStrategy)}, true)
+		if err != nil {
+			return err
+		}
+		return c.config.Promote(c.Url, c.Tag, append(opts, root.WithStrategy(root.PromoteStrategy(strategy)))...)
+	}
+	return err
 }
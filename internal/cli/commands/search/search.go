@@ -0,0 +1,48 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+)
+
+type SearchCommand struct {
+	Term string `arg:"" optional:"" help:"Text to look for in package names, descriptions and tags; lists every package when empty"`
+
+	config *root.RootConfig
+}
+
+func (c *SearchCommand) BeforeApply() error {
+	config, err := root.LoadRootConfig()
+	if err != nil {
+		return err
+	}
+	c.config = config
+	return nil
+}
+
+func (c *SearchCommand) Run() error {
+	if len(c.config.Registries) == 0 {
+		return fmt.Errorf("no registries configured, add one under registries in %s", c.config.Path)
+	}
+	entries, err := c.config.SearchPackages(c.Term)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No packages match %q\n", c.Term)
+		return nil
+	}
+	for _, entry := range entries {
+		fmt.Printf("📦 %s", entry.Name)
+		if entry.Description != "" {
+			fmt.Printf(" - %s", entry.Description)
+		}
+		if len(entry.Tags) > 0 {
+			fmt.Printf(" [%s]", strings.Join(entry.Tags, ", "))
+		}
+		fmt.Printf("\n    %s\n", entry.Url)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package status
+
+import (
+	"fmt"
+
+	"github.com/core-stack/zetten-cli/internal/core/project"
+)
+
+type StatusCommand struct {
+	Urls []string `help:"Comma-separated list of package URLs to check" short:"u" long:"url" sep:","`
+
+	config *project.ProjectConfig
+}
+
+func (c *StatusCommand) BeforeApply() error {
+	config, err := project.LoadProjectConfig("zetten.yml")
+	if err != nil {
+		return err
+	}
+	c.config = config
+	return nil
+}
+
+func (c *StatusCommand) Run() error {
+	var statuses []*project.PackageStatus
+	if len(c.Urls) == 0 {
+		var err error
+		statuses, err = c.config.Status()
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, url := range c.Urls {
+			status, err := c.config.PackageStatus(url)
+			if err != nil {
+				return err
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	for _, status := range statuses {
+		switch {
+		case status.Missing:
+			fmt.Printf("⚠️ %s@%s is not installed\n", status.Url, status.Version)
+		case status.Clean():
+			fmt.Printf("✅ %s@%s\n", status.Url, status.Version)
+		default:
+			fmt.Printf("📝 %s@%s has local changes\n", status.Url, status.Version)
+			printFiles("M", status.Modified)
+			printFiles("A", status.Added)
+			printFiles("D", status.Deleted)
+		}
+	}
+	return nil
+}
+
+func printFiles(prefix string, files []string) {
+	for _, f := range files {
+		fmt.Printf("    %s %s\n", prefix, f)
+	}
+}
@@ -3,6 +3,9 @@ package sync
 import "github.com/core-stack/zetten-cli/internal/core/project"
 
 type SyncCommand struct {
+	Force     bool `help:"Back up local changes of installed packages and overwrite them" short:"f" xor:"local"`
+	KeepLocal bool `help:"Merge local changes of installed packages into the synced versions" short:"k" xor:"local"`
+
 	config *project.ProjectConfig
 }
 
@@ -16,5 +19,5 @@ func (c *SyncCommand) BeforeApply() error {
 }
 
 func (c *SyncCommand) Run() error {
-	return c.config.Sync()
+	return c.config.Sync(project.WithLocalChanges(project.LocalChangesPolicyFromFlags(c.Force, c.KeepLocal)))
 }
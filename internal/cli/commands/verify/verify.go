@@ -0,0 +1,58 @@
+package verify
+
+import (
+	"fmt"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+)
+
+type VerifyCommand struct {
+	Fix bool `help:"Remove corrupt snapshots so the next install rebuilds them" short:"f" long:"fix"`
+
+	config *root.RootConfig
+}
+
+func (c *VerifyCommand) BeforeApply() error {
+	config, err := root.LoadRootConfig()
+	if err != nil {
+		return err
+	}
+	c.config = config
+	return nil
+}
+
+func (c *VerifyCommand) Run() error {
+	store := c.config.Store()
+	snapshots, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	broken := 0
+	for _, snapshot := range snapshots {
+		corrupt, err := store.Verify(snapshot)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s@%.12s", snapshot.Key.Url, snapshot.Key.Commit)
+		if len(corrupt) == 0 {
+			fmt.Printf("✅ %s\n", name)
+			continue
+		}
+		broken++
+		fmt.Printf("❌ %s does not match its digest\n", name)
+		for _, path := range corrupt {
+			fmt.Printf("    %s\n", path)
+		}
+		if c.Fix {
+			if err := store.Remove(snapshot, corrupt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if broken > 0 && !c.Fix {
+		return fmt.Errorf("%d corrupt snapshots, run `zetten verify --fix` to remove them", broken)
+	}
+	return nil
+}
@@ -11,9 +11,6 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
-	"golang.org/x/crypto/ssh/knownhosts"
 )
 
 type CloneOptions struct {
@@ -61,53 +58,13 @@ func CloneRepo(repoUrl, destination string, opts ...CloneOpt) error {
 
 	var authMethod transport.AuthMethod
 	var err error
-
 	if options.AuthMethod == "" || options.Credentials == "" {
-		authConf, err := auth.Loader.FindAuth(options.RepoUrl)
-		if err != nil {
-			if err != auth.ErrNoAuthConfigFound {
-				return fmt.Errorf("failed to load auth for url %s: %w", options.RepoUrl, err)
-			} else {
-				options.AuthMethod = "none"
-				fmt.Printf("no auth found for %s, cloning without authentication\n", options.RepoUrl)
-			}
-		} else {
-			options.AuthMethod = authConf.Method
-			options.Credentials = authConf.Credentials
-		}
+		options.RepoUrl, authMethod, err = auth.ResolveAuth(options.RepoUrl)
+	} else {
+		authMethod, err = auth.AuthMethod(&auth.AuthConfig{Method: options.AuthMethod, Credentials: options.Credentials})
 	}
-
-	switch options.AuthMethod {
-	case "token":
-		authMethod = &http.BasicAuth{
-			Username: "git",
-			Password: options.Credentials,
-		}
-	case "basic":
-		parts := strings.Split(options.Credentials, ":")
-		if len(parts) != 2 {
-			return fmt.Errorf("basic auth credentials must be in format 'username:password'")
-		}
-		authMethod = &http.BasicAuth{
-			Username: parts[0],
-			Password: parts[1],
-		}
-	case "ssh":
-		publicKeys, err := ssh.NewPublicKeysFromFile("git", options.Credentials, "")
-		if err != nil {
-			return fmt.Errorf("failed to parse SSH key: %w", err)
-		}
-
-		hostKeyCallback, err := knownhosts.New(os.ExpandEnv("$HOME/.ssh/known_hosts"))
-		if err == nil {
-			publicKeys.HostKeyCallback = hostKeyCallback
-		}
-
-		authMethod = publicKeys
-	case "none":
-		authMethod = nil
-	default:
-		return fmt.Errorf("invalid auth method: %s", options.AuthMethod)
+	if err != nil {
+		return err
 	}
 
 	var referenceName string
@@ -10,6 +10,7 @@ import (
 
 type PromptInputOpts struct {
 	DefaultValue string
+	Mask         bool
 }
 
 type CreatePromptInputOpts func(*PromptInputOpts)
@@ -20,6 +21,13 @@ func WithDefaultValue(defaultValue string) CreatePromptInputOpts {
 	}
 }
 
+// WithMask hides the typed characters, for secrets.
+func WithMask() CreatePromptInputOpts {
+	return func(pio *PromptInputOpts) {
+		pio.Mask = true
+	}
+}
+
 func PromptInput(label string, opts ...CreatePromptInputOpts) (string, error) {
 	options := &PromptInputOpts{}
 	for _, opt := range opts {
@@ -30,6 +38,9 @@ func PromptInput(label string, opts ...CreatePromptInputOpts) (string, error) {
 		Label:   label,
 		Default: options.DefaultValue,
 	}
+	if options.Mask {
+		prompt.Mask = '*'
+	}
 	result, err := prompt.Run()
 	if err != nil {
 		return "", fmt.Errorf("❌ Error reading %s: %v\n", label, err)
@@ -7,10 +7,16 @@ import (
 	"github.com/core-stack/zetten-cli/internal/util"
 )
 
+// DEFAULT_PACKAGE_FILE is the manifest a package may ship at its root.
+const DEFAULT_PACKAGE_FILE = "zetten-package.yml"
+
 type PackageFile struct {
-	Tag        string `yaml:"tag,omitempty"`
-	Repository string `yaml:"repository"`
-	Path       string `yaml:"-"`
+	Tag          string            `yaml:"tag,omitempty"`
+	Repository   string            `yaml:"repository"`
+	Description  string            `yaml:"description,omitempty"`
+	License      string            `yaml:"license,omitempty"`
+	Dependencies map[string]string `yaml:"dependencies,omitempty"`
+	Path         string            `yaml:"-"`
 }
 
 func (f *PackageFile) Save() error {
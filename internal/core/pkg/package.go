@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/core-stack/zetten-cli/internal/core/file"
+	"github.com/goccy/go-yaml"
 )
 
 type PackageConfig struct {
@@ -20,3 +21,13 @@ func LoadPackageConfig(path string) (*PackageConfig, error) {
 	cfg.Path = path
 	return cfg, nil
 }
+
+// ParsePackageFile reads a manifest from its content, such as a file read
+// from a package source.
+func ParsePackageFile(data []byte) (*PackageFile, error) {
+	var f PackageFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("❌ Failed to parse package file: %w", err)
+	}
+	return &f, nil
+}
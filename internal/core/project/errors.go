@@ -0,0 +1,7 @@
+package project
+
+import "errors"
+
+var (
+	ErrLocalChanges = errors.New("package has local changes")
+)
@@ -13,6 +13,24 @@ type ProjectFile struct {
 	Version      string     `yaml:"version"`
 	Dependencies Dependency `yaml:"dependencies"`
 	PackagesPath string     `yaml:"packagesPath"`
+	// Keep lists paths, relative to each package directory, that survive a
+	// reinstall. Entries are glob patterns; a pattern without a slash also
+	// matches by file or directory name at any depth.
+	Keep []string `yaml:"keep,omitempty"`
+	// Patches maps a package url to a patch file, relative to the project
+	// file, that is applied on every install.
+	Patches map[string]string `yaml:"patches,omitempty"`
+	// Link is how packages are installed: copy (default), symlink or
+	// hardlink. Links overrides it per package url.
+	Link  string            `yaml:"link,omitempty"`
+	Links map[string]string `yaml:"links,omitempty"`
+	// Baselines maps the url of a local package copied from its directory to
+	// the revision of that directory recorded at install, which local
+	// changes are compared against.
+	Baselines map[string]string `yaml:"baselines,omitempty"`
+	// Revisions maps a package url to the revision its version resolved to
+	// at install, such as the commit of a tag.
+	Revisions map[string]string `yaml:"revisions,omitempty"`
 
 	Path string `yaml:"-"`
 }
@@ -37,6 +55,65 @@ func (p *ProjectFile) RemoveDependency(url string, autoSave bool) error {
 		p.Dependencies = make(map[string]string)
 	}
 	delete(p.Dependencies, url)
+	delete(p.Links, url)
+	delete(p.Baselines, url)
+	delete(p.Revisions, url)
+	if autoSave {
+		return p.Save()
+	}
+	return nil
+}
+func (p *ProjectFile) SetPatch(url, patchPath string, autoSave bool) error {
+	if p.Patches == nil {
+		p.Patches = make(map[string]string)
+	}
+	if patchPath == "" {
+		delete(p.Patches, url)
+	} else {
+		p.Patches[url] = patchPath
+	}
+	if autoSave {
+		return p.Save()
+	}
+	return nil
+}
+func (p *ProjectFile) SetLink(url, mode string, autoSave bool) error {
+	if p.Links == nil {
+		p.Links = make(map[string]string)
+	}
+	if mode == "" || mode == "copy" {
+		delete(p.Links, url)
+	} else {
+		p.Links[url] = mode
+	}
+	if autoSave {
+		return p.Save()
+	}
+	return nil
+}
+func (p *ProjectFile) SetBaseline(url, revision string, autoSave bool) error {
+	if p.Baselines == nil {
+		p.Baselines = make(map[string]string)
+	}
+	if revision == "" {
+		delete(p.Baselines, url)
+	} else {
+		p.Baselines[url] = revision
+	}
+	if autoSave {
+		return p.Save()
+	}
+	return nil
+}
+func (p *ProjectFile) SetRevision(url, revision string, autoSave bool) error {
+	if p.Revisions == nil {
+		p.Revisions = make(map[string]string)
+	}
+	if revision == "" {
+		delete(p.Revisions, url)
+	} else {
+		p.Revisions[url] = revision
+	}
 	if autoSave {
 		return p.Save()
 	}
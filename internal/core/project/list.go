@@ -0,0 +1,108 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/core-stack/zetten-cli/internal/core/pkg"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+// InstalledPackage describes a dependency of the project as installed.
+type InstalledPackage struct {
+	Url      string `json:"url"`
+	Version  string `json:"version"`
+	Revision string `json:"revision,omitempty"`
+	Dir      string `json:"dir"`
+	Link     string `json:"link"`
+	Size     int64  `json:"size"`
+	Present  bool   `json:"present"`
+	Modified bool   `json:"modified"`
+	// Dependencies are the packages required by the manifest of the
+	// package, filled in by List when asked for the tree.
+	Dependencies []*InstalledPackage `json:"dependencies,omitempty"`
+}
+
+// List returns the dependencies of the project sorted by url. With tree, the
+// dependencies declared by each package manifest are listed below it.
+func (p *ProjectConfig) List(tree bool) ([]*InstalledPackage, error) {
+	urls := util.MapKeys[map[string]string](p.Dependencies)
+	sort.Strings(urls)
+
+	var packages []*InstalledPackage
+	for _, url := range urls {
+		installed, err := p.describe(url, p.Dependencies[url])
+		if err != nil {
+			return nil, err
+		}
+		if tree {
+			p.addManifestDependencies(installed, map[string]bool{url: true})
+		}
+		packages = append(packages, installed)
+	}
+	return packages, nil
+}
+
+func (p *ProjectConfig) describe(url, version string) (*InstalledPackage, error) {
+	installed := &InstalledPackage{
+		Url:     url,
+		Version: version,
+		Dir:     p.PackageDir(url),
+		Link:    string(p.linkMode(url)),
+	}
+	// the revision recorded at install, the source may have moved since
+	if p.isWorkdir(url, version) {
+		installed.Revision = p.Baselines[url]
+	} else {
+		installed.Revision = p.Revisions[url]
+	}
+	if _, err := os.Stat(installed.Dir); err != nil {
+		return installed, nil
+	}
+	installed.Present = true
+
+	size, err := util.DirSize(installed.Dir)
+	if err != nil {
+		return nil, err
+	}
+	installed.Size = size
+	if status, err := p.PackageStatus(url); err == nil {
+		installed.Modified = !status.Clean()
+	}
+	return installed, nil
+}
+
+// addManifestDependencies reads the manifest shipped in the installed package
+// and describes its dependencies, recursively. Packages already in the chain
+// are skipped to break cycles.
+func (p *ProjectConfig) addManifestDependencies(parent *InstalledPackage, seen map[string]bool) {
+	if !parent.Present {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(parent.Dir, pkg.DEFAULT_PACKAGE_FILE))
+	if err != nil {
+		return
+	}
+	manifest, err := pkg.ParsePackageFile(data)
+	if err != nil {
+		return
+	}
+	urls := util.MapKeys(manifest.Dependencies)
+	sort.Strings(urls)
+	for _, url := range urls {
+		if seen[url] {
+			continue
+		}
+		child := &InstalledPackage{Url: url, Version: manifest.Dependencies[url], Dir: p.PackageDir(url)}
+		if version, ok := p.Dependencies[url]; ok {
+			if described, err := p.describe(url, version); err == nil {
+				child = described
+			}
+		}
+		seen[url] = true
+		p.addManifestDependencies(child, seen)
+		delete(seen, url)
+		parent.Dependencies = append(parent.Dependencies, child)
+	}
+}
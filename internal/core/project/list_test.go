@@ -0,0 +1,51 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	cfg := newInstalledProject(t)
+	dep := "https://example.com/org/dep.git"
+	missing := "https://example.com/org/missing.git"
+	cfg.Dependencies[missing] = "v1.0.0"
+	require.NoError(t, cfg.Install(dep, "v1.0.0"))
+	os.WriteFile(filepath.Join(cfg.PackageDir(localTestURL), "zetten-package.yml"),
+		[]byte("dependencies:\n  "+dep+": v1.0.0\n  https://example.com/org/other.git: v3.0.0\n"), 0644)
+	// list reads the revisions recorded at install, without the source
+	cfg.Root.(*MockRootConfig).Offline = true
+
+	packages, err := cfg.List(false)
+	require.NoError(t, err)
+	require.Len(t, packages, 3)
+
+	assert.Equal(t, dep, packages[0].Url)
+	assert.True(t, packages[0].Present)
+	assert.False(t, packages[0].Modified)
+
+	assert.Equal(t, missing, packages[1].Url)
+	assert.False(t, packages[1].Present)
+	assert.Empty(t, packages[1].Revision)
+
+	ui := packages[2]
+	assert.Equal(t, localTestURL, ui.Url)
+	assert.Equal(t, "v1.0.0", ui.Revision)
+	assert.True(t, ui.Modified)
+	assert.Equal(t, int64(len("ONE\ntwo\nthree\n")+len("dependencies:\n  "+dep+": v1.0.0\n  https://example.com/org/other.git: v3.0.0\n")), ui.Size)
+	assert.Empty(t, ui.Dependencies)
+
+	packages, err = cfg.List(true)
+	require.NoError(t, err)
+	ui = packages[2]
+	require.Len(t, ui.Dependencies, 2)
+	assert.True(t, ui.Dependencies[0].Present)
+	assert.Equal(t, "https://example.com/org/other.git", ui.Dependencies[1].Url)
+	assert.False(t, ui.Dependencies[1].Present)
+	assert.Equal(t, string(project.LinkCopy), ui.Link)
+}
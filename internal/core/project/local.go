@@ -0,0 +1,122 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+// LocalChangesPolicy decides what install does with local modifications of
+// an installed package.
+type LocalChangesPolicy int
+
+const (
+	// AbortOnLocalChanges refuses to overwrite a modified package.
+	AbortOnLocalChanges LocalChangesPolicy = iota
+	// BackupLocalChanges copies the modified package to a backup directory
+	// and overwrites it.
+	BackupLocalChanges
+	// MergeLocalChanges three-way merges the local modifications into the
+	// installed version.
+	MergeLocalChanges
+)
+
+// LocalChangesPolicyFromFlags maps the --force and --keep-local flags to a
+// policy.
+func LocalChangesPolicyFromFlags(force, keepLocal bool) LocalChangesPolicy {
+	switch {
+	case force:
+		return BackupLocalChanges
+	case keepLocal:
+		return MergeLocalChanges
+	default:
+		return AbortOnLocalChanges
+	}
+}
+
+type InstallOptions struct {
+	LocalChanges LocalChangesPolicy
+	// Link, when set, changes and records how the package is installed.
+	Link LinkMode
+}
+
+type InstallOpt func(*InstallOptions)
+
+func WithLocalChanges(policy LocalChangesPolicy) InstallOpt {
+	return func(o *InstallOptions) {
+		o.LocalChanges = policy
+	}
+}
+
+func WithLink(mode LinkMode) InstallOpt {
+	return func(o *InstallOptions) {
+		o.Link = mode
+	}
+}
+
+// localEdits holds what is needed to merge local modifications after the
+// new version has been copied over them.
+type localEdits struct {
+	base  map[string][]byte
+	local map[string][]byte
+}
+
+// protectLocalChanges applies policy to the local modifications of an
+// installed package before it is overwritten. It returns the edits to merge
+// back when policy is MergeLocalChanges.
+func (p *ProjectConfig) protectLocalChanges(url string, policy LocalChangesPolicy) (*localEdits, error) {
+	if _, installed := p.Dependencies[url]; !installed {
+		return nil, nil
+	}
+	status, err := p.PackageStatus(url)
+	if err != nil {
+		return nil, err
+	}
+	if status.Missing || status.Clean() {
+		return nil, nil
+	}
+
+	switch policy {
+	case BackupLocalChanges:
+		backup := filepath.Join(root.DEFAULT_ROOT_PATH, "backups", time.Now().Format("20060102-150405"), filepath.FromSlash(util.PackageName(p.SourceURL(url))))
+		if err := util.CopyDir(status.Dir, backup, []string{".git"}); err != nil {
+			return nil, fmt.Errorf("failed to back up local changes of %s: %w", url, err)
+		}
+		fmt.Printf("💾 Local changes of %s saved to %s\n", url, backup)
+		return nil, nil
+	case MergeLocalChanges:
+		base, err := p.expectedFiles(url, status.Version)
+		if err != nil {
+			return nil, err
+		}
+		local, err := util.ReadDirFiles(status.Dir, []string{".git"})
+		if err != nil {
+			return nil, err
+		}
+		return &localEdits{base: base, local: local}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s has %d modified, %d added and %d deleted files, use --force to back them up and overwrite or --keep-local to keep them",
+			ErrLocalChanges, url, len(status.Modified), len(status.Added), len(status.Deleted))
+	}
+}
+
+// restoreLocalEdits merges edits into the freshly installed version tag of url.
+func (p *ProjectConfig) restoreLocalEdits(url, tag string, edits *localEdits) error {
+	installed, err := p.expectedFiles(url, tag)
+	if err != nil {
+		return err
+	}
+	merged, conflicts := util.MergeFiles(edits.base, edits.local, installed, util.MergeLabels{Ours: "local", Theirs: tag})
+	if err := util.WriteMergedFiles(p.PackageDir(url), merged); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w in %s: %s", root.ErrMergeConflict, url, strings.Join(conflicts, ", "))
+	}
+	fmt.Printf("🔀 Kept local changes of %s\n", url)
+	return nil
+}
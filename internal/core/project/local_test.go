@@ -0,0 +1,80 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const localTestURL = "https://example.com/org/ui.git"
+
+// newInstalledProject returns a project with ui@v1.0.0 installed and its
+// main.go edited locally.
+func newInstalledProject(t *testing.T) *project.ProjectConfig {
+	t.Helper()
+	tmp := t.TempDir()
+	cfg := &project.ProjectConfig{
+		ProjectFile: project.ProjectFile{
+			Path:         filepath.Join(tmp, "zetten.yml"),
+			PackagesPath: filepath.Join(tmp, "packages"),
+			Dependencies: project.Dependency{},
+		},
+		Root: &MockRootConfig{T: t, Tags: map[string]map[string][]byte{
+			"v1.0.0": {"main.go": []byte("one\ntwo\nthree\n")},
+			"v2.0.0": {"main.go": []byte("one\ntwo\nTHREE\n")},
+		}},
+	}
+	require.NoError(t, cfg.Install(localTestURL, "v1.0.0"))
+	os.WriteFile(filepath.Join(cfg.PackageDir(localTestURL), "main.go"), []byte("ONE\ntwo\nthree\n"), 0644)
+	return cfg
+}
+
+func readInstalled(t *testing.T, cfg *project.ProjectConfig) string {
+	data, err := os.ReadFile(filepath.Join(cfg.PackageDir(localTestURL), "main.go"))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestInstall_AbortsOnLocalChanges(t *testing.T) {
+	cfg := newInstalledProject(t)
+
+	err := cfg.Install(localTestURL, "v2.0.0")
+	assert.ErrorIs(t, err, project.ErrLocalChanges)
+	assert.Equal(t, "ONE\ntwo\nthree\n", readInstalled(t, cfg))
+	assert.Equal(t, "v1.0.0", cfg.Dependencies[localTestURL])
+}
+
+func TestInstall_BacksUpLocalChanges(t *testing.T) {
+	originalPath := root.DEFAULT_ROOT_PATH
+	root.DEFAULT_ROOT_PATH = t.TempDir()
+	defer func() { root.DEFAULT_ROOT_PATH = originalPath }()
+	cfg := newInstalledProject(t)
+
+	err := cfg.Install(localTestURL, "v2.0.0", project.WithLocalChanges(project.BackupLocalChanges))
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nTHREE\n", readInstalled(t, cfg))
+
+	backups, err := filepath.Glob(filepath.Join(root.DEFAULT_ROOT_PATH, "backups", "*", "org", "ui", "main.go"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	data, _ := os.ReadFile(backups[0])
+	assert.Equal(t, "ONE\ntwo\nthree\n", string(data))
+}
+
+func TestInstall_MergesLocalChanges(t *testing.T) {
+	cfg := newInstalledProject(t)
+
+	err := cfg.Install(localTestURL, "v2.0.0", project.WithLocalChanges(project.MergeLocalChanges))
+	require.NoError(t, err)
+	assert.Equal(t, "ONE\ntwo\nTHREE\n", readInstalled(t, cfg))
+	assert.Equal(t, "v2.0.0", cfg.Dependencies[localTestURL])
+
+	err = cfg.Sync(project.WithLocalChanges(project.MergeLocalChanges))
+	require.NoError(t, err)
+	assert.Equal(t, "ONE\ntwo\nTHREE\n", readInstalled(t, cfg))
+}
@@ -0,0 +1,140 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+const DEFAULT_PATCHES_DIR = "patches"
+
+// patchFilePath resolves a patch path from the project file, which is
+// relative to the directory of the project file.
+func (p *ProjectConfig) patchFilePath(rel string) string {
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(filepath.Dir(p.Path), rel)
+}
+
+func (p *ProjectConfig) loadPatch(url string) ([]util.FilePatch, error) {
+	rel, ok := p.Patches[url]
+	if !ok {
+		return nil, nil
+	}
+	data, err := os.ReadFile(p.patchFilePath(rel))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch of %s: %w", url, err)
+	}
+	patches, err := util.ParsePatch(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch %s: %w", rel, err)
+	}
+	return patches, nil
+}
+
+// expectedFiles returns the files of url at tag with its patch applied, which
+// is what a clean install looks like. Hunks that do not apply are ignored.
+func (p *ProjectConfig) expectedFiles(url, tag string) (map[string][]byte, error) {
+	files, err := p.sourceFiles(url, tag)
+	if err != nil {
+		return nil, err
+	}
+	patches, err := p.loadPatch(url)
+	if err != nil || patches == nil {
+		return files, err
+	}
+
+	patched, _ := util.ApplyPatch(files, patches)
+	expected := make(map[string][]byte, len(files))
+	for path, data := range files {
+		expected[path] = data
+	}
+	for path, f := range patched {
+		if f.Deleted {
+			delete(expected, path)
+		} else {
+			expected[path] = f.Data
+		}
+	}
+	return expected, nil
+}
+
+// applyPatch applies the patch of url, if any, to its freshly installed
+// version tag.
+func (p *ProjectConfig) applyPatch(url, tag string) error {
+	patches, err := p.loadPatch(url)
+	if err != nil || patches == nil {
+		return err
+	}
+	files, err := p.sourceFiles(url, tag)
+	if err != nil {
+		return err
+	}
+	patched, conflicts := util.ApplyPatch(files, patches)
+	if err := util.WriteMergedFiles(p.PackageDir(url), patched); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s no longer applies to %s@%s in %s, run `zetten patch` again once fixed",
+			util.ErrPatchDoesNotApply, p.Patches[url], url, tag, strings.Join(conflicts, ", "))
+	}
+	fmt.Printf("🩹 Applied %s to %s\n", p.Patches[url], url)
+	return nil
+}
+
+// CreatePatch captures the local changes of an installed package in a patch
+// file under the patches directory and registers it in the project file.
+// Without local changes an existing patch is removed.
+func (p *ProjectConfig) CreatePatch(url string) (string, error) {
+	version, ok := p.Dependencies[url]
+	if !ok {
+		return "", fmt.Errorf("%s is not a dependency", url)
+	}
+	pinned, err := p.sourceFiles(url, version)
+	if err != nil {
+		return "", err
+	}
+	local, err := util.ReadDirFiles(p.PackageDir(url), []string{".git"})
+	if err != nil {
+		return "", err
+	}
+	for path := range local {
+		if p.isKept(path) {
+			delete(local, path)
+		}
+	}
+
+	diff, skipped := util.DiffFiles(pinned, local)
+	for _, path := range skipped {
+		fmt.Printf("⚠️ Binary file %s cannot be patched, skipping\n", path)
+	}
+
+	rel := util.Or(p.Patches[url], filepath.Join(DEFAULT_PATCHES_DIR, patchName(p.SourceURL(url))))
+	if diff == "" {
+		if _, ok := p.Patches[url]; ok {
+			if err := os.Remove(p.patchFilePath(rel)); err != nil && !os.IsNotExist(err) {
+				return "", err
+			}
+		}
+		return "", p.SetPatch(url, "", true)
+	}
+
+	header := fmt.Sprintf("# zetten patch for %s at %s\n", url, version)
+	target := p.patchFilePath(rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(target, []byte(header+diff), 0644); err != nil {
+		return "", err
+	}
+	return rel, p.SetPatch(url, filepath.ToSlash(rel), true)
+}
+
+func patchName(url string) string {
+	name := strings.Trim(util.PackageName(url), "/")
+	return strings.ReplaceAll(name, "/", "-") + ".patch"
+}
@@ -0,0 +1,50 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePatch_AppliedOnInstall(t *testing.T) {
+	cfg := newInstalledProject(t)
+	mock := cfg.Root.(*MockRootConfig)
+
+	rel, err := cfg.CreatePatch(localTestURL)
+	require.NoError(t, err)
+	assert.Equal(t, "patches/org-ui.patch", rel)
+	assert.Equal(t, rel, cfg.Patches[localTestURL])
+	assert.FileExists(t, filepath.Join(filepath.Dir(cfg.Path), rel))
+
+	status, err := cfg.PackageStatus(localTestURL)
+	require.NoError(t, err)
+	assert.True(t, status.Clean(), "patched files are not local changes")
+
+	// upgrading re-applies the patch on top of the new version
+	mock.Tags["v1.1.0"] = map[string][]byte{"main.go": []byte("one\ntwo\nthree\nfour\n")}
+	require.NoError(t, cfg.Install(localTestURL, "v1.1.0"))
+	assert.Equal(t, "ONE\ntwo\nthree\nfour\n", readInstalled(t, cfg))
+
+	// upstream changing the patch context makes the patch stop applying
+	err = cfg.Install(localTestURL, "v2.0.0")
+	assert.ErrorIs(t, err, util.ErrPatchDoesNotApply)
+	assert.Equal(t, "one\ntwo\nTHREE\n", readInstalled(t, cfg))
+	assert.Equal(t, "v2.0.0", cfg.Dependencies[localTestURL])
+}
+
+func TestCreatePatch_RemovedWithoutLocalChanges(t *testing.T) {
+	cfg := newInstalledProject(t)
+	rel, err := cfg.CreatePatch(localTestURL)
+	require.NoError(t, err)
+
+	os.WriteFile(filepath.Join(cfg.PackageDir(localTestURL), "main.go"), []byte("one\ntwo\nthree\n"), 0644)
+	empty, err := cfg.CreatePatch(localTestURL)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+	assert.NotContains(t, cfg.Patches, localTestURL)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(cfg.Path), rel))
+}
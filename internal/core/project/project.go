@@ -2,10 +2,10 @@ package project
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
-	"path"
 	"path/filepath"
-	"strings"
 
 	"github.com/core-stack/zetten-cli/internal/core/file"
 	"github.com/core-stack/zetten-cli/internal/core/root"
@@ -18,39 +18,124 @@ type ProjectConfig struct {
 	Root root.IRootConfig
 }
 
-func (p *ProjectConfig) CopyFromRoot(url string) error {
-	path := strings.TrimSuffix(util.ExtractPathFromURL(url), ".git")
-	destination := filepath.Join(p.PackagesPath, path)
-	return p.Root.CopyRootFiles(url, destination, []string{".git"})
+// PackageDir returns the directory a package is installed in.
+func (p *ProjectConfig) PackageDir(url string) string {
+	return filepath.Join(p.PackagesPath, filepath.FromSlash(util.PackageName(p.SourceURL(url))))
 }
 
-func (p *ProjectConfig) Install(url, tag string) error {
+// CopyFromRoot replaces the package directory with a fresh copy of url at tag
+// from the root store, so files removed upstream do not linger. Paths
+// matching Keep are carried over from the previous install.
+func (p *ProjectConfig) CopyFromRoot(url, tag string) error {
+	dir := p.PackageDir(url)
+	return util.ReplaceDir(dir, func(tmp string) error {
+		if err := p.Root.CopyRootFiles(p.SourceURL(url), tag, tmp, []string{".git"}); err != nil {
+			return err
+		}
+		return p.copyKeptFiles(dir, tmp)
+	})
+}
+
+func (p *ProjectConfig) copyKeptFiles(source, target string) error {
+	if len(p.Keep) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		if !p.isKept(filepath.ToSlash(rel)) {
+			return nil
+		}
+		dst := filepath.Join(target, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		return util.CopyFile(path, dst)
+	})
+}
+
+// isKept reports whether rel, or one of its parent directories, matches a
+// Keep pattern.
+func (p *ProjectConfig) isKept(rel string) bool {
+	return util.MatchPaths(p.Keep, rel)
+}
+
+func (p *ProjectConfig) Install(url, tag string, opts ...InstallOpt) error {
+	options := &InstallOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	if url == "" {
 		return errors.New("url is required")
 	}
-	if tag == "" {
-		return errors.New("tag is required")
+	var revision string
+	if !p.isWorkdir(url, tag) {
+		var err error
+		revision, err = p.Root.Resolve(p.SourceURL(url), tag)
+		if err != nil {
+			return err
+		}
+		if tag == "" {
+			// sources without tags, such as archives, are pinned to their
+			// current revision
+			tag = revision
+		}
 	}
 
-	_, err := p.Root.OpenOrClonePackage(url)
-	if err != nil {
+	link := p.linkMode(url)
+	if options.Link != "" {
+		link = options.Link
+	}
+	if err := validateLink(link); err != nil {
 		return err
 	}
-	_, err = p.Root.Checkout(url, tag)
+
+	edits, err := p.protectLocalChanges(url, options.LocalChanges)
 	if err != nil {
 		return err
 	}
 
-	err = p.CopyFromRoot(url)
-	if err != nil {
+	if options.Link != "" {
+		p.SetLink(url, string(options.Link), false)
+	}
+	if p.isWorkdir(url, tag) {
+		if err := p.recordBaseline(url, link); err != nil {
+			return err
+		}
+	}
+	if err = p.materialize(url, tag, link); err != nil {
 		return err
 	}
+	var patchErr error
+	if link == LinkSymlink {
+		if _, ok := p.Patches[url]; ok {
+			fmt.Printf("⚠️ %s is symlinked, not applying %s to its source, use copy or hardlink instead\n", url, p.Patches[url])
+		}
+	} else {
+		patchErr = p.applyPatch(url, tag)
+	}
 
+	p.SetRevision(url, revision, false)
 	if err = p.AddDependency(url, tag, true); err != nil {
 		return errors.New("error saving new dependency")
 	}
+	p.register()
 
-	return nil
+	if edits != nil {
+		if err := p.restoreLocalEdits(url, tag, edits); err != nil {
+			return errors.Join(patchErr, err)
+		}
+	}
+	return patchErr
 }
 
 func (p *ProjectConfig) Uninstall(urls []string) error {
@@ -64,7 +149,7 @@ func (p *ProjectConfig) Uninstall(urls []string) error {
 		if url == "" {
 			continue
 		}
-		err := os.RemoveAll(filepath.Join(p.PackagesPath, util.ExtractPathFromURL(url)))
+		err := os.RemoveAll(p.PackageDir(url))
 		if err != nil {
 			return err
 		}
@@ -104,18 +189,37 @@ func (p *ProjectConfig) cleanPackageFolders() error {
 	return nil
 }
 
-func (p *ProjectConfig) Sync() error {
+func (p *ProjectConfig) Sync(opts ...InstallOpt) error {
 	for url, version := range p.Dependencies {
-		if err := p.Install(url, version); err != nil {
+		if err := p.Install(url, version, opts...); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (p *ProjectConfig) Promote(url, tag string) error {
+func (p *ProjectConfig) Promote(url, tag string, opts ...root.PromoteOpt) error {
 	version := p.Dependencies[url]
-	err := p.Root.Promote(url, version, tag, path.Join(p.PackagesPath, util.ExtractPathFromURL(url)))
+	if p.isWorkdir(url, version) {
+		return fmt.Errorf("%s is installed from its local directory, commit and tag it there instead", url)
+	}
+	if util.IsArchive(url) {
+		return fmt.Errorf("%s is an archive and cannot be promoted", url)
+	}
+	if p.linkMode(url) == LinkSymlink {
+		return fmt.Errorf("%s is symlinked to a read-only snapshot, reinstall it with --link copy to edit and promote it", url)
+	}
+	opts = append([]root.PromoteOpt{root.WithProject(p.Name, p.Version), root.WithKeep(p.Keep...)}, opts...)
+	err := p.Root.Promote(p.SourceURL(url), version, tag, p.PackageDir(url), opts...)
+	var conflict *root.ConflictError
+	if errors.As(err, &conflict) {
+		// the package now holds the latest version plus the local edits
+		p.Dependencies[url] = conflict.Base
+		if saveErr := p.Save(); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
 	if err != nil {
 		return err
 	}
@@ -155,3 +259,15 @@ func NewProjectConfig(path, name, version, packagesPath string) (*ProjectConfig,
 	err = cfg.Save()
 	return &cfg, err
 }
+
+// register records the project directory in the root config once it
+// installs a package, so commands like info can tell which projects use it.
+func (p *ProjectConfig) register() {
+	dir, err := filepath.Abs(filepath.Dir(p.Path))
+	if err != nil {
+		return
+	}
+	if err := p.Root.AddProject(dir, true); err != nil {
+		fmt.Printf("⚠️ Could not register project %s: %v\n", dir, err)
+	}
+}
@@ -45,12 +45,14 @@ func TestInstall_Success(t *testing.T) {
 			PackagesPath: pkgs,
 			Dependencies: project.Dependency{},
 		},
-		Root: &MockRootConfig{},
+		Root: &MockRootConfig{T: t},
 	}
 
 	err := cfg.Install("github.com/user/repo", "v1.0.0")
 	assert.NoError(t, err)
 	assert.Equal(t, "v1.0.0", cfg.Dependencies["github.com/user/repo"])
+	// o projeto é registrado na config raiz ao instalar
+	assert.Equal(t, []string{tmp}, cfg.Root.(*MockRootConfig).Projects)
 }
 
 func TestInstall_MissingURL(t *testing.T) {
@@ -66,7 +68,7 @@ func TestInstall_CheckoutFails(t *testing.T) {
 			PackagesPath: t.TempDir(),
 			Dependencies: project.Dependency{},
 		},
-		Root: &MockRootConfig{},
+		Root: &MockRootConfig{T: t},
 	}
 	err := cfg.Install("github.com/user/repo", "error")
 	assert.Error(t, err)
@@ -105,10 +107,42 @@ func TestSync_Success(t *testing.T) {
 				"github.com/user/repo2": "v2.0.0",
 			},
 		},
-		Root: &MockRootConfig{},
+		Root: &MockRootConfig{T: t},
 	}
 
 	err := cfg.Sync()
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(cfg.Dependencies))
 }
+
+func TestInstall_ReplacesPackageDir(t *testing.T) {
+	tmp := t.TempDir()
+	url := "https://example.com/org/ui.git"
+	cfg := &project.ProjectConfig{
+		ProjectFile: project.ProjectFile{
+			Path:         filepath.Join(tmp, "project.yaml"),
+			PackagesPath: filepath.Join(tmp, "packages"),
+			Dependencies: project.Dependency{},
+			Keep:         []string{"local.env", "config/*.json"},
+		},
+		Root: &MockRootConfig{T: t, Tags: map[string]map[string][]byte{
+			"v1.0.0": {"main.go": []byte("v1"), "old.go": []byte("v1")},
+			"v2.0.0": {"main.go": []byte("v2")},
+		}},
+	}
+	assert.NoError(t, cfg.Install(url, "v1.0.0"))
+
+	dir := cfg.PackageDir(url)
+	os.MkdirAll(filepath.Join(dir, "config"), 0755)
+	os.WriteFile(filepath.Join(dir, "config", "dev.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(dir, "local.env"), []byte("KEY=1"), 0644)
+
+	assert.NoError(t, cfg.Install(url, "v2.0.0"))
+	assert.FileExists(t, filepath.Join(dir, "main.go"))
+	assert.NoFileExists(t, filepath.Join(dir, "old.go"))
+	assert.FileExists(t, filepath.Join(dir, "config", "dev.json"))
+	assert.FileExists(t, filepath.Join(dir, "local.env"))
+
+	entries, _ := os.ReadDir(filepath.Dir(dir))
+	assert.Len(t, entries, 1, "temporary directories should be cleaned up")
+}
@@ -2,33 +2,116 @@ package project_test
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"testing"
 
-	"github.com/go-git/go-git/v5"
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
 )
 
 // MockRootConfig finge o comportamento real
-type MockRootConfig struct{}
+type MockRootConfig struct {
+	// T guarda os snapshots em diretórios temporários do teste
+	T *testing.T
+	// Files são os arquivos da versão fixada de qualquer pacote
+	Files map[string][]byte
+	// Tags sobrescreve Files para versões específicas
+	Tags map[string]map[string][]byte
 
-func (m *MockRootConfig) OpenOrClonePackage(url string) (*git.Repository, error) {
-	return nil, nil
+	// Projects são os diretórios registrados por AddProject
+	Projects []string
+	// Offline faz Resolve falhar, como sem acesso à origem
+	Offline bool
+
+	snapshots map[string]string
+	recorded  map[string]map[string][]byte
 }
-func (m *MockRootConfig) Checkout(url, tag string) (*git.Repository, error) {
-	if tag == "error" {
-		return nil, errors.New("checkout failed")
+
+func (m *MockRootConfig) AddProject(dir string, autoSave bool) error {
+	if !slices.Contains(m.Projects, dir) {
+		m.Projects = append(m.Projects, dir)
+	}
+	return nil
+}
+
+func (m *MockRootConfig) filesAt(tag string) map[string][]byte {
+	if files, ok := m.Tags[tag]; ok {
+		return files
 	}
-	return nil, nil
+	return m.Files
+}
+
+func (m *MockRootConfig) Versions(url string) ([]string, error) {
+	return util.MapKeys(m.Tags), nil
 }
-func (m *MockRootConfig) CopyRootFiles(url, destination string, ignore []string) error {
-	return os.MkdirAll(destination, 0755)
+func (m *MockRootConfig) Resolve(url, version string) (string, error) {
+	if m.Offline {
+		return "", errors.New("source unreachable")
+	}
+	switch version {
+	case "error":
+		return "", errors.New("checkout failed")
+	case "":
+		return "sha256:mock", nil
+	}
+	return version, nil
 }
-func (m *MockRootConfig) Promote(url, tag, newTag, packageDir string) error {
+func (m *MockRootConfig) CopyRootFiles(url, tag, destination string, ignore []string) error {
+	if tag == "error" {
+		return errors.New("checkout failed")
+	}
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return err
+	}
+	for name, data := range m.filesAt(tag) {
+		path := filepath.Join(destination, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
 	return nil
 }
-func (m *MockRootConfig) HasPackage(url string) bool {
-	return true
+func (m *MockRootConfig) Promote(url, tag, newTag, packageDir string, opts ...root.PromoteOpt) error {
+	return nil
+}
+func (m *MockRootConfig) ReadPackageFiles(url, tag string) (map[string][]byte, error) {
+	if files, ok := m.recorded[tag]; ok {
+		return files, nil
+	}
+	return m.filesAt(tag), nil
+}
+func (m *MockRootConfig) Record(url string, files map[string][]byte) (string, error) {
+	if m.recorded == nil {
+		m.recorded = map[string]map[string][]byte{}
+	}
+	revision := fmt.Sprintf("sha256:recorded-%d", len(m.recorded))
+	m.recorded[revision] = files
+	return revision, nil
 }
 
-func (m *MockRootConfig) BuildRootPackagePath(url string) string {
-	return "/fake/path"
+func (m *MockRootConfig) Snapshot(url, tag string) (string, error) {
+	if m.snapshots == nil {
+		m.snapshots = map[string]string{}
+	}
+	if dir, ok := m.snapshots[tag]; ok {
+		return dir, nil
+	}
+	dir := m.T.TempDir()
+	for name, data := range m.filesAt(tag) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, data, 0444); err != nil {
+			return "", err
+		}
+	}
+	m.snapshots[tag] = dir
+	return dir, nil
 }
@@ -0,0 +1,132 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+// LOCAL_VERSION pins a local package to the current content of its directory
+// instead of a tag, for side by side development.
+const LOCAL_VERSION = root.LOCAL_VERSION
+
+type LinkMode string
+
+const (
+	// LinkCopy copies the package files into the packages path.
+	LinkCopy LinkMode = "copy"
+	// LinkSymlink points the package directory at its source: the local
+	// directory of workdir packages, a read-only snapshot otherwise.
+	LinkSymlink LinkMode = "symlink"
+	// LinkHardlink hard links every file of the source into the package
+	// directory. Files written by zetten, such as patches, replace the link.
+	LinkHardlink LinkMode = "hardlink"
+	// LinkReflink clones every file of the source on copy-on-write
+	// filesystems and copies it elsewhere.
+	LinkReflink LinkMode = "reflink"
+)
+
+// SourceURL returns the url the root cache clones url from. Local paths are
+// made absolute, relative ones being resolved against the project file.
+func (p *ProjectConfig) SourceURL(url string) string {
+	if !util.IsLocalPath(url) {
+		return url
+	}
+	return util.LocalPath(url, filepath.Dir(p.Path))
+}
+
+// isWorkdir reports whether url is used straight from its local directory
+// rather than from a tag in the root cache.
+func (p *ProjectConfig) isWorkdir(url, tag string) bool {
+	return tag == LOCAL_VERSION && util.IsLocalPath(url) && !util.IsArchive(url)
+}
+
+// linkMode returns how url is installed: its own mode, else the project
+// default, else copy.
+func (p *ProjectConfig) linkMode(url string) LinkMode {
+	if mode, ok := p.Links[url]; ok && mode != "" {
+		return LinkMode(mode)
+	}
+	if p.Link != "" {
+		return LinkMode(p.Link)
+	}
+	return LinkCopy
+}
+
+func validateLink(mode LinkMode) error {
+	switch mode {
+	case LinkCopy, LinkSymlink, LinkHardlink, LinkReflink:
+		return nil
+	default:
+		return fmt.Errorf("unknown link mode %q", mode)
+	}
+}
+
+// sourceFiles returns the files of url at tag, read from the root cache. A
+// workdir package is read as recorded at install, or from its local directory
+// when it is symlinked.
+func (p *ProjectConfig) sourceFiles(url, tag string) (map[string][]byte, error) {
+	if p.isWorkdir(url, tag) {
+		if baseline, ok := p.Baselines[url]; ok {
+			return p.Root.ReadPackageFiles(p.SourceURL(url), baseline)
+		}
+		return util.ReadDirFiles(p.SourceURL(url), []string{".git"})
+	}
+	return p.Root.ReadPackageFiles(p.SourceURL(url), tag)
+}
+
+// recordBaseline records the current files of the local directory of url in
+// the root cache, so edits made there after install are not mistaken for
+// local changes of the copy. Symlinked packages are their directory and need
+// none.
+func (p *ProjectConfig) recordBaseline(url string, mode LinkMode) error {
+	if mode == LinkSymlink {
+		return p.SetBaseline(url, "", false)
+	}
+	source := p.SourceURL(url)
+	files, err := util.ReadDirFiles(source, []string{".git"})
+	if err != nil {
+		return err
+	}
+	revision, err := p.Root.Record(source, files)
+	if err != nil {
+		return err
+	}
+	return p.SetBaseline(url, revision, false)
+}
+
+// materialize replaces the package directory with the files of url at tag,
+// copied or linked according to mode.
+func (p *ProjectConfig) materialize(url, tag string, mode LinkMode) error {
+	if mode == LinkCopy && !p.isWorkdir(url, tag) {
+		return p.CopyFromRoot(url, tag)
+	}
+
+	source := p.SourceURL(url)
+	if !p.isWorkdir(url, tag) {
+		snapshot, err := p.Root.Snapshot(source, tag)
+		if err != nil {
+			return err
+		}
+		source = snapshot
+	}
+	dir := p.PackageDir(url)
+	if mode == LinkSymlink {
+		return util.ReplaceWithSymlink(dir, source)
+	}
+	place := util.CopyFile
+	switch mode {
+	case LinkHardlink:
+		place = util.LinkFile
+	case LinkReflink:
+		place = util.ReflinkFile
+	}
+	return util.ReplaceDir(dir, func(tmp string) error {
+		if err := util.PlaceDir(source, tmp, []string{".git"}, place); err != nil {
+			return err
+		}
+		return p.copyKeptFiles(dir, tmp)
+	})
+}
@@ -0,0 +1,161 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLocalProject returns a project next to a ../shared-ui package directory.
+func newLocalProject(t *testing.T) (*project.ProjectConfig, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	shared := filepath.Join(tmp, "shared-ui")
+	require.NoError(t, os.MkdirAll(filepath.Join(shared, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "button.go"), []byte("button\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, ".git", "HEAD"), []byte("ref\n"), 0644))
+
+	cfg := &project.ProjectConfig{
+		ProjectFile: project.ProjectFile{
+			Path:         filepath.Join(tmp, "app", "zetten.yml"),
+			PackagesPath: filepath.Join(tmp, "app", "packages"),
+			Dependencies: project.Dependency{},
+		},
+		Root: &MockRootConfig{T: t},
+	}
+	require.NoError(t, os.MkdirAll(filepath.Dir(cfg.Path), 0755))
+	return cfg, shared
+}
+
+func TestInstall_LocalCopy(t *testing.T) {
+	cfg, shared := newLocalProject(t)
+
+	require.NoError(t, cfg.Install("../shared-ui", project.LOCAL_VERSION))
+
+	dir := cfg.PackageDir("../shared-ui")
+	assert.Equal(t, filepath.Join(cfg.PackagesPath, filepath.FromSlash(util.PackageName(shared))), dir)
+	data, err := os.ReadFile(filepath.Join(dir, "button.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "button\n", string(data))
+	assert.NoDirExists(t, filepath.Join(dir, ".git"))
+	assert.Equal(t, project.LOCAL_VERSION, cfg.Dependencies["../shared-ui"])
+	assert.Contains(t, cfg.Baselines, "../shared-ui")
+
+	// the copy is compared with the directory as installed, so edits made
+	// there are not local changes
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "button.go"), []byte("button v2\n"), 0644))
+	status, err := cfg.PackageStatus("../shared-ui")
+	require.NoError(t, err)
+	assert.True(t, status.Clean())
+
+	require.NoError(t, cfg.Sync())
+	data, err = os.ReadFile(filepath.Join(dir, "button.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "button v2\n", string(data))
+}
+
+func TestInstall_LocalCopyKeepsEditsOfCopy(t *testing.T) {
+	cfg, shared := newLocalProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "button.go"), []byte("one\ntwo\nthree\n"), 0644))
+	require.NoError(t, cfg.Install("../shared-ui", project.LOCAL_VERSION))
+
+	dir := cfg.PackageDir("../shared-ui")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "button.go"), []byte("ONE\ntwo\nthree\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "button.go"), []byte("one\ntwo\nTHREE\n"), 0644))
+
+	status, err := cfg.PackageStatus("../shared-ui")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"button.go"}, status.Modified)
+	assert.ErrorIs(t, cfg.Sync(), project.ErrLocalChanges)
+
+	require.NoError(t, cfg.Sync(project.WithLocalChanges(project.MergeLocalChanges)))
+	data, err := os.ReadFile(filepath.Join(dir, "button.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "ONE\ntwo\nTHREE\n", string(data))
+}
+
+func TestPackageDir_LocalSameName(t *testing.T) {
+	cfg, _ := newLocalProject(t)
+
+	assert.NotEqual(t, cfg.PackageDir("../a/ui"), cfg.PackageDir("../b/ui"))
+}
+
+func TestInstall_LocalSymlink(t *testing.T) {
+	cfg, shared := newLocalProject(t)
+	url := "file://" + shared
+
+	require.NoError(t, cfg.Install(url, project.LOCAL_VERSION, project.WithLink(project.LinkSymlink)))
+
+	target, err := os.Readlink(cfg.PackageDir(url))
+	require.NoError(t, err)
+	assert.Equal(t, shared, target)
+	assert.Equal(t, "symlink", cfg.Links[url])
+	assert.NotContains(t, cfg.Baselines, url)
+
+	status, err := cfg.PackageStatus(url)
+	require.NoError(t, err)
+	assert.True(t, status.Clean())
+
+	// reinstalling as a copy replaces the link
+	require.NoError(t, cfg.Install(url, project.LOCAL_VERSION, project.WithLink(project.LinkCopy)))
+	info, err := os.Lstat(cfg.PackageDir(url))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	assert.NotContains(t, cfg.Links, url)
+}
+
+func TestInstall_HardlinkSnapshot(t *testing.T) {
+	cfg := newInstalledProject(t)
+	cfg.Patches = nil
+	require.NoError(t, cfg.Install(localTestURL, "v1.0.0", project.WithLink(project.LinkHardlink), project.WithLocalChanges(project.MergeLocalChanges)))
+	assert.Equal(t, "hardlink", cfg.Links[localTestURL])
+	assert.Equal(t, "ONE\ntwo\nthree\n", readInstalled(t, cfg))
+
+	// the merged local edit replaced the link instead of writing through it
+	snapshot, err := cfg.Root.Snapshot(localTestURL, "v1.0.0")
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(snapshot, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\n", string(data))
+}
+
+func TestInstall_ProjectLinkDefault(t *testing.T) {
+	cfg := newInstalledProject(t)
+	cfg.Link = "symlink"
+
+	require.NoError(t, cfg.Install(localTestURL, "v2.0.0", project.WithLocalChanges(project.BackupLocalChanges)))
+	target, err := os.Readlink(cfg.PackageDir(localTestURL))
+	require.NoError(t, err)
+	snapshot, _ := cfg.Root.Snapshot(localTestURL, "v2.0.0")
+	assert.Equal(t, snapshot, target)
+	assert.Equal(t, "one\ntwo\nTHREE\n", readInstalled(t, cfg))
+	assert.Error(t, cfg.Promote(localTestURL, "v2.1.0"))
+}
+
+func TestInstall_UnknownLinkMode(t *testing.T) {
+	cfg := newInstalledProject(t)
+
+	assert.Error(t, cfg.Install(localTestURL, "v1.0.0", project.WithLink("reflink")))
+}
+
+func TestPromote_RefusesLocalVersion(t *testing.T) {
+	cfg, _ := newLocalProject(t)
+	require.NoError(t, cfg.Install("../shared-ui", project.LOCAL_VERSION))
+
+	assert.Error(t, cfg.Promote("../shared-ui", "v1.0.0"))
+}
+
+func TestInstall_ArchivePinsChecksum(t *testing.T) {
+	cfg := newInstalledProject(t)
+	url := "https://example.com/releases/ui-1.0.0.tar.gz"
+
+	require.NoError(t, cfg.Install(url, ""))
+	assert.Equal(t, "sha256:mock", cfg.Dependencies[url])
+	assert.Equal(t, filepath.Join(cfg.PackagesPath, "releases", "ui-1.0.0"), cfg.PackageDir(url))
+	assert.Error(t, cfg.Promote(url, "v1.0.0"))
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"bytes"
+	"os"
+	"sort"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+// PackageStatus lists the local modifications of an installed package
+// compared with its pinned version.
+type PackageStatus struct {
+	Url      string
+	Version  string
+	Dir      string
+	Missing  bool
+	Modified []string
+	Added    []string
+	Deleted  []string
+}
+
+func (s *PackageStatus) Clean() bool {
+	return !s.Missing && len(s.Modified) == 0 && len(s.Added) == 0 && len(s.Deleted) == 0
+}
+
+// PackageStatus compares the installed files of url with the files of its
+// pinned version in the root cache, with its patch applied. Paths matching
+// Keep are user owned and never reported as added.
+func (p *ProjectConfig) PackageStatus(url string) (*PackageStatus, error) {
+	version := p.Dependencies[url]
+	status := &PackageStatus{Url: url, Version: version, Dir: p.PackageDir(url)}
+	if _, err := os.Stat(status.Dir); os.IsNotExist(err) {
+		status.Missing = true
+		return status, nil
+	}
+
+	pinned, err := p.expectedFiles(url, version)
+	if err != nil {
+		return nil, err
+	}
+	local, err := util.ReadDirFiles(status.Dir, []string{".git"})
+	if err != nil {
+		return nil, err
+	}
+
+	for path, data := range local {
+		if p.isKept(path) {
+			continue
+		}
+		original, ok := pinned[path]
+		switch {
+		case !ok:
+			status.Added = append(status.Added, path)
+		case !bytes.Equal(original, data):
+			status.Modified = append(status.Modified, path)
+		}
+	}
+	for path := range pinned {
+		if _, ok := local[path]; !ok {
+			status.Deleted = append(status.Deleted, path)
+		}
+	}
+	sort.Strings(status.Modified)
+	sort.Strings(status.Added)
+	sort.Strings(status.Deleted)
+	return status, nil
+}
+
+// Status returns the status of every dependency, sorted by url.
+func (p *ProjectConfig) Status() ([]*PackageStatus, error) {
+	urls := util.MapKeys[map[string]string](p.Dependencies)
+	sort.Strings(urls)
+
+	var statuses []*PackageStatus
+	for _, url := range urls {
+		status, err := p.PackageStatus(url)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
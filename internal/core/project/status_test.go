@@ -0,0 +1,50 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	tmp := t.TempDir()
+	cfg := &project.ProjectConfig{
+		ProjectFile: project.ProjectFile{
+			PackagesPath: filepath.Join(tmp, "packages"),
+			Dependencies: project.Dependency{
+				"https://example.com/org/ui.git":   "v1.0.0",
+				"https://example.com/org/core.git": "v2.0.0",
+			},
+		},
+		Root: &MockRootConfig{T: t, Files: map[string][]byte{
+			"README.md":   []byte("readme"),
+			"src/main.go": []byte("package main"),
+			"src/old.go":  []byte("package main"),
+		}},
+	}
+
+	dir := cfg.PackageDir("https://example.com/org/ui.git")
+	assert.Equal(t, filepath.Join(tmp, "packages", "org", "ui"), dir)
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n// edited"), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "new.go"), []byte("package main"), 0644)
+
+	statuses, err := cfg.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, "https://example.com/org/core.git", statuses[0].Url)
+	assert.True(t, statuses[0].Missing)
+	assert.False(t, statuses[0].Clean())
+
+	ui := statuses[1]
+	assert.Equal(t, "v1.0.0", ui.Version)
+	assert.Equal(t, []string{"src/main.go"}, ui.Modified)
+	assert.Equal(t, []string{"src/new.go"}, ui.Added)
+	assert.Equal(t, []string{"src/old.go"}, ui.Deleted)
+}
@@ -0,0 +1,55 @@
+package root
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+const CHECKSUM_PREFIX = "sha256:"
+
+// MAX_DOWNLOAD_SIZE caps the size of a downloaded archive, which is read
+// into memory before being extracted.
+var MAX_DOWNLOAD_SIZE int64 = 256 << 20
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// readArchive returns the archive content, from the store when hash is
+// known, otherwise downloaded or read from disk. A stored archive that does
+// not match its hash is removed, so the fresh copy replaces it.
+func (r *RootConfig) readArchive(url, hash string) ([]byte, error) {
+	if hash != "" {
+		store := r.Store()
+		data, err := store.ReadObject(hash)
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			if err := store.RemoveObject(hash); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return os.ReadFile(strings.TrimPrefix(url, "file://"))
+	}
+
+	fmt.Printf("📦 Downloading %s\n", url)
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	data, err := util.ReadAllLimited(resp.Body, MAX_DOWNLOAD_SIZE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,94 @@
+package root_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestArchiveSource_PinsChecksum(t *testing.T) {
+	useTempStore(t)
+	r := &root.RootConfig{}
+	archive := filepath.Join(t.TempDir(), "ui.zip")
+	writeZip(t, archive, map[string]string{"ui/button.go": "button"})
+
+	checksum, err := r.Resolve(archive, "")
+	require.NoError(t, err)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", checksum)
+	dir, err := r.Snapshot(archive, checksum)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "button.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "button", string(data))
+
+	// the pinned archive is served from the store even once gone
+	require.NoError(t, os.Remove(archive))
+	again, err := r.Snapshot(archive, checksum)
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+
+	writeZip(t, archive, map[string]string{"ui/button.go": "changed"})
+	_, err = r.Resolve(archive, "sha256:0000")
+	assert.ErrorIs(t, err, root.ErrChecksumMismatch)
+
+	_, err = r.OpenOrClonePackage(archive)
+	assert.ErrorIs(t, err, root.ErrNotGitPackage)
+}
+
+func TestArchiveSource_ReplacesCorruptObject(t *testing.T) {
+	useTempStore(t)
+	r := &root.RootConfig{}
+	archive := filepath.Join(t.TempDir(), "ui.zip")
+	writeZip(t, archive, map[string]string{"ui/button.go": "button"})
+	checksum, err := r.Resolve(archive, "")
+	require.NoError(t, err)
+
+	hash := strings.TrimPrefix(checksum, root.CHECKSUM_PREFIX)
+	object := filepath.Join(root.DEFAULT_ROOT_STORE_PATH, "objects", hash[:2], hash)
+	require.NoError(t, os.Chmod(object, 0644))
+	require.NoError(t, os.WriteFile(object, []byte("corrupt"), 0644))
+
+	again, err := r.Resolve(archive, checksum)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, again)
+	_, err = r.Store().ReadObject(hash)
+	assert.NoError(t, err)
+}
+
+func TestArchiveSource_RejectsOversizedDownload(t *testing.T) {
+	useTempStore(t)
+	originalSize := root.MAX_DOWNLOAD_SIZE
+	root.MAX_DOWNLOAD_SIZE = 16
+	defer func() { root.MAX_DOWNLOAD_SIZE = originalSize }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("x"), 64))
+	}))
+	defer server.Close()
+
+	r := &root.RootConfig{}
+	_, err := r.Resolve(server.URL+"/ui.zip", "")
+	assert.ErrorIs(t, err, util.ErrArchiveTooLarge)
+}
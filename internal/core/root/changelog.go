@@ -0,0 +1,219 @@
+package root
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const DEFAULT_CHANGELOG_FILE = "CHANGELOG.md"
+
+type ChangelogConfig struct {
+	Disabled bool   `yaml:"disabled,omitempty"`
+	File     string `yaml:"file,omitempty"`
+}
+
+func (c *ChangelogConfig) fileName() string {
+	if c.File != "" {
+		return c.File
+	}
+	return DEFAULT_CHANGELOG_FILE
+}
+
+type changeEntry struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+	Hash        string
+}
+
+// changelogGroups lists the conventional-commit types in the order their
+// sections are rendered. Unknown types are collected under "Other Changes".
+var changelogGroups = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+	{"", "Other Changes"},
+}
+
+var conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+func parseConventionalCommit(message, hash string) changeEntry {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	entry := changeEntry{Description: strings.TrimSpace(lines[0]), Hash: hash}
+
+	if m := conventionalCommitRegex.FindStringSubmatch(entry.Description); m != nil {
+		entry.Type = strings.ToLower(m[1])
+		entry.Scope = m[2]
+		entry.Breaking = m[3] == "!"
+		entry.Description = m[4]
+	}
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			entry.Breaking = true
+		}
+	}
+	return entry
+}
+
+func (e changeEntry) String() string {
+	var b strings.Builder
+	b.WriteString("- ")
+	if e.Scope != "" {
+		fmt.Fprintf(&b, "**%s:** ", e.Scope)
+	}
+	b.WriteString(e.Description)
+	if e.Hash != "" {
+		fmt.Fprintf(&b, " (%s)", e.Hash)
+	}
+	return b.String()
+}
+
+// buildChangelogSection renders the markdown section for tag, grouping the
+// entries by conventional-commit type.
+func buildChangelogSection(tag string, date time.Time, entries []changeEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s (%s)\n", tag, date.Format("2006-01-02"))
+
+	var breaking []string
+	for _, e := range entries {
+		if e.Breaking {
+			breaking = append(breaking, e.String())
+		}
+	}
+	if len(breaking) > 0 {
+		fmt.Fprintf(&b, "\n### ⚠ BREAKING CHANGES\n\n%s\n", strings.Join(breaking, "\n"))
+	}
+
+	for _, group := range changelogGroups {
+		var lines []string
+		for _, e := range entries {
+			if groupType(e.Type) == group.Type {
+				lines = append(lines, e.String())
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "\n### %s\n\n%s\n", group.Title, strings.Join(lines, "\n"))
+		}
+	}
+	return b.String()
+}
+
+func groupType(t string) string {
+	for _, group := range changelogGroups {
+		if group.Type == t {
+			return t
+		}
+	}
+	return ""
+}
+
+// commitsSince returns the commits reachable from tips but not from base,
+// newest first, as git log base..tips would.
+func commitsSince(repo *git.Repository, base plumbing.Hash, tips []plumbing.Hash) ([]*object.Commit, error) {
+	seen := map[plumbing.Hash]bool{}
+	iter, err := repo.Log(&git.LogOptions{From: base})
+	if err != nil {
+		return nil, err
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		seen[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*object.Commit
+	for _, tip := range tips {
+		if seen[tip] {
+			continue
+		}
+		c, err := repo.CommitObject(tip)
+		if err != nil {
+			return nil, err
+		}
+		err = object.NewCommitPreorderIter(c, seen, nil).ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			commits = append(commits, c)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return commits, nil
+}
+
+// collectChanges returns the changelog entries for a promotion: the
+// promotion message followed by the commits since base, such as upstream
+// releases and the local changes committed by the merge strategy.
+func collectChanges(repo *git.Repository, base plumbing.Hash, tips []plumbing.Hash, promotionMessage string) ([]changeEntry, error) {
+	commits, err := commitsSince(repo, base, tips)
+	if err != nil {
+		return nil, err
+	}
+	entries := []changeEntry{parseConventionalCommit(promotionMessage, "")}
+	for _, c := range commits {
+		entries = append(entries, parseConventionalCommit(c.Message, c.Hash.String()[:7]))
+	}
+	return entries, nil
+}
+
+// updateChangelog inserts section at the top of the changelog at path,
+// replacing an existing section for the same tag.
+func updateChangelog(path, tag, section string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := string(data)
+
+	header := "# Changelog\n"
+	body := content
+	if strings.HasPrefix(content, "# ") {
+		if idx := strings.Index(content, "\n"); idx >= 0 {
+			header, body = content[:idx+1], content[idx+1:]
+		} else {
+			header, body = content+"\n", ""
+		}
+	}
+	body = removeChangelogSection(body, tag)
+
+	result := header + "\n" + section
+	if rest := strings.TrimSpace(body); rest != "" {
+		result += "\n" + rest + "\n"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(result), 0644)
+}
+
+func removeChangelogSection(body, tag string) string {
+	lines := strings.Split(body, "\n")
+	var out []string
+	skipping := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			skipping = line == "## "+tag || strings.HasPrefix(line, "## "+tag+" ")
+		}
+		if !skipping {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
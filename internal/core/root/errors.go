@@ -0,0 +1,36 @@
+package root
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrMissingAuthor     = errors.New("no commit author configured: set promotion.author in the zetten config or user.name/user.email in git config")
+	ErrTagExists         = errors.New("tag already exists")
+	ErrVersionNotGreater = errors.New("new version must be greater than the current one")
+	ErrUpstreamChanged   = errors.New("upstream has newer versions")
+	ErrMergeConflict     = errors.New("merge conflict")
+	ErrChecksumMismatch  = errors.New("archive checksum mismatch")
+	ErrNotGitPackage     = errors.New("package is not a git repository")
+	ErrPushNotSupported  = errors.New("source does not support pushing")
+	ErrPackageNotFound   = errors.New("package not found in registry")
+)
+
+// ConflictError reports files that could not be merged automatically. The
+// merged content, with conflict markers, is left in Dir which from then on is
+// based on Base.
+type ConflictError struct {
+	Files []string
+	Base  string
+	Dir   string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v in %s: resolve the conflicts in %s and promote again, the package is now based on %s", ErrMergeConflict, strings.Join(e.Files, ", "), e.Dir, e.Base)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrMergeConflict
+}
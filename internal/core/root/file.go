@@ -9,9 +9,16 @@ import (
 )
 
 type RootFile struct {
-	ZettenProjects []string   `yaml:"zettenProjects"`
-	Path           string     `yaml:"-"`
-	Mirror         [][]string `yaml:"mirror"`
+	ZettenProjects []string      `yaml:"zettenProjects"`
+	Path           string        `yaml:"-"`
+	Mirror         []MirrorGroup `yaml:"mirror"`
+	// MirrorIgnore lists gitignore style patterns left out of every mirror
+	// group.
+	MirrorIgnore []string        `yaml:"mirrorIgnore,omitempty"`
+	Promotion    PromotionConfig `yaml:"promotion,omitempty"`
+	// Registries lists package indexes, local YAML files or http(s) urls,
+	// used to install packages by name.
+	Registries []string `yaml:"registries,omitempty"`
 }
 
 func (f *RootFile) Save() error {
@@ -20,6 +27,9 @@ func (f *RootFile) Save() error {
 }
 
 func (r *RootFile) AddProject(dir string, autoSave bool) error {
+	if slices.Contains(r.ZettenProjects, dir) {
+		return nil
+	}
 	r.ZettenProjects = append(r.ZettenProjects, dir)
 	if autoSave {
 		return r.Save()
@@ -41,7 +51,7 @@ func (r *RootFile) RemoveProject(dir string, autoSave bool) error {
 }
 
 func (r *RootFile) AddMirror(paths []string, autoSave bool) error {
-	r.Mirror = append(r.Mirror, paths)
+	r.Mirror = append(r.Mirror, MirrorGroup{Paths: paths})
 	if autoSave {
 		return r.Save()
 	}
@@ -49,10 +59,10 @@ func (r *RootFile) AddMirror(paths []string, autoSave bool) error {
 }
 
 func (r *RootFile) RemoveMirror(path string, autoSave bool) error {
-	for i, p := range r.Mirror {
-		for j, m := range p {
+	for i, group := range r.Mirror {
+		for j, m := range group.Paths {
 			if m == path {
-				r.Mirror[i] = slices.Delete(r.Mirror[i], j, j+1)
+				r.Mirror[i].Paths = slices.Delete(r.Mirror[i].Paths, j, j+1)
 			}
 		}
 	}
@@ -0,0 +1,177 @@
+package root
+
+import (
+	"fmt"
+
+	"github.com/core-stack/zetten-cli/internal/auth"
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type PromoteStrategy string
+
+const (
+	// AbortStrategy refuses to promote when upstream released newer versions.
+	AbortStrategy PromoteStrategy = ""
+	// RebaseStrategy replays the local edits on top of the latest version.
+	RebaseStrategy PromoteStrategy = "rebase"
+	// MergeStrategy commits the local edits on the base version and merges
+	// them with the latest version.
+	MergeStrategy PromoteStrategy = "merge"
+)
+
+// fetchUpstream updates tags and remote branches of the cached package of
+// url. A failing fetch is reported but not fatal, so promoting works offline.
+func fetchUpstream(repo *git.Repository, url string) {
+	if _, err := repo.Remote("origin"); err != nil {
+		return
+	}
+	_, method, err := auth.ResolveAuth(url)
+	if err != nil {
+		fmt.Printf("⚠️ Could not fetch upstream, using cached tags: %v\n", err)
+		return
+	}
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       method,
+		RefSpecs: []gitconfig.RefSpec{
+			"+refs/heads/*:refs/remotes/origin/*",
+			"+refs/tags/*:refs/tags/*",
+		},
+		Tags: git.AllTags,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		fmt.Printf("⚠️ Could not fetch upstream, using cached tags: %v\n", err)
+	}
+}
+
+func listTags(repo *git.Repository) ([]string, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+	return tags, err
+}
+
+// checkPromotion validates newTag against the tags of repo and returns the
+// latest version released on the same major line as baseTag when it is
+// newer than baseTag.
+func checkPromotion(repo *git.Repository, baseTag, newTag string) (string, error) {
+	tags, err := listTags(repo)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if tag == newTag {
+			return "", fmt.Errorf("%w: %s", ErrTagExists, newTag)
+		}
+	}
+
+	base, err := util.ParseVersion(baseTag)
+	if err != nil {
+		return "", nil
+	}
+	latest := base
+	for _, v := range util.SortVersions(tags) {
+		if v.Major == base.Major && v.Compare(latest) > 0 {
+			latest = v
+		}
+	}
+
+	if next, err := util.ParseVersion(newTag); err == nil && next.Compare(latest) <= 0 {
+		return "", fmt.Errorf("%w: %s is not greater than %s", ErrVersionNotGreater, newTag, latest)
+	}
+	if latest.Original == baseTag {
+		warnUntaggedCommits(repo, baseTag)
+		return "", nil
+	}
+	return latest.Original, nil
+}
+
+// warnUntaggedCommits reports remote branches that moved past baseTag
+// without a release.
+func warnUntaggedCommits(repo *git.Repository, baseTag string) {
+	baseHash, err := resolveCommit(repo, baseTag)
+	if err != nil {
+		return
+	}
+	base, err := repo.CommitObject(baseHash)
+	if err != nil {
+		return
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return
+	}
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsRemote() || ref.Hash() == baseHash {
+			return nil
+		}
+		tip, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return nil
+		}
+		if ok, _ := base.IsAncestor(tip); ok {
+			fmt.Printf("⚠️ %s has unreleased commits after %s\n", ref.Name().Short(), baseTag)
+		}
+		return nil
+	})
+}
+
+func treeFiles(repo *git.Repository, hash plumbing.Hash) (map[string][]byte, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		files[f.Name] = []byte(content)
+		return nil
+	})
+	return files, err
+}
+
+// mergePackage three-way merges the local package directory with the latest
+// version, using base as the common ancestor. It returns the merged content of
+// every file that differs from latest and the paths that conflict.
+func mergePackage(repo *git.Repository, base, latest plumbing.Hash, packageDir, latestTag string, keep []string) (map[string]util.MergedFile, []string, error) {
+	baseFiles, err := treeFiles(repo, base)
+	if err != nil {
+		return nil, nil, err
+	}
+	theirFiles, err := treeFiles(repo, latest)
+	if err != nil {
+		return nil, nil, err
+	}
+	ourFiles, err := util.ReadDirFiles(packageDir, []string{".git"})
+	if err != nil {
+		return nil, nil, err
+	}
+	// kept paths are local only, upstream keeps its own version of them
+	for _, files := range []map[string][]byte{baseFiles, theirFiles, ourFiles} {
+		for p := range files {
+			if util.MatchPaths(keep, p) {
+				delete(files, p)
+			}
+		}
+	}
+
+	merged, conflicts := util.MergeFiles(baseFiles, ourFiles, theirFiles, util.MergeLabels{Ours: "local", Theirs: latestTag})
+	return merged, conflicts, nil
+}
@@ -0,0 +1,157 @@
+package root
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/pkg"
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/goccy/go-yaml"
+)
+
+// TagInfo is a released version and when it was released.
+type TagInfo struct {
+	Name string
+	Date time.Time
+}
+
+// PackageInfo describes a package without installing it.
+type PackageInfo struct {
+	Url string
+	// Entry is the registry entry of the package, if any.
+	Entry *RegistryEntry
+	// Manifest is the package file of the latest version, if it ships one.
+	Manifest      *pkg.PackageFile
+	Latest        string
+	Tags          []TagInfo
+	DefaultBranch string
+	// Projects lists the registered projects depending on the package, with
+	// the version they use.
+	Projects map[string]string
+}
+
+// Info gathers what is known about the package at url, which may also be a
+// registry name.
+func (r *RootConfig) Info(target string) (*PackageInfo, error) {
+	info := &PackageInfo{Url: target}
+	if !strings.Contains(target, "/") {
+		entry, err := r.LookupPackage(target)
+		if err != nil {
+			return nil, err
+		}
+		info.Entry, info.Url = entry, entry.Url
+	} else if entries, err := r.LoadRegistry(); err == nil {
+		for _, entry := range entries {
+			if entry.Url == target {
+				info.Entry = &entry
+				break
+			}
+		}
+	}
+
+	src, err := r.Source(info.Url)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := src.Versions()
+	if err != nil {
+		return nil, err
+	}
+	if sorted := util.SortVersions(versions); len(sorted) > 0 {
+		info.Latest = sorted[len(sorted)-1].Original
+	} else if len(versions) > 0 {
+		info.Latest = versions[len(versions)-1]
+	}
+
+	if git, ok := src.(*gitSource); ok {
+		repo, err := r.OpenOrClonePackage(git.url)
+		if err != nil {
+			return nil, err
+		}
+		info.Tags = tagInfos(repo, versions)
+		info.DefaultBranch = defaultBranch(repo)
+	} else {
+		for _, v := range versions {
+			info.Tags = append(info.Tags, TagInfo{Name: v})
+		}
+	}
+
+	if info.Latest != "" {
+		if files, err := r.ReadPackageFiles(info.Url, info.Latest); err == nil {
+			if data, ok := files[pkg.DEFAULT_PACKAGE_FILE]; ok {
+				if info.Manifest, err = pkg.ParsePackageFile(data); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	info.Projects = r.projectsUsing(info.Url)
+	return info, nil
+}
+
+// tagInfos dates tags by their tagger, or by their commit for lightweight
+// tags, newest first.
+func tagInfos(repo *git.Repository, tags []string) []TagInfo {
+	var infos []TagInfo
+	for _, name := range tags {
+		info := TagInfo{Name: name}
+		if ref, err := repo.Tag(name); err == nil {
+			if tag, err := repo.TagObject(ref.Hash()); err == nil {
+				info.Date = tag.Tagger.When
+			} else if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+				info.Date = commit.Committer.When
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Date.After(infos[j].Date) })
+	return infos
+}
+
+// defaultBranch returns the branch origin/HEAD points to, falling back to the
+// branch created by the clone.
+func defaultBranch(repo *git.Repository) string {
+	if ref, err := repo.Reference("refs/remotes/origin/HEAD", false); err == nil && ref.Type() == plumbing.SymbolicReference {
+		return strings.TrimPrefix(ref.Target().String(), "refs/remotes/origin/")
+	}
+	branches, err := repo.Branches()
+	if err != nil {
+		return ""
+	}
+	var name string
+	branches.ForEach(func(ref *plumbing.Reference) error {
+		if name == "" {
+			name = ref.Name().Short()
+		}
+		return nil
+	})
+	return name
+}
+
+// projectsUsing reads the project file of every registered project and
+// returns the ones depending on url.
+func (r *RootConfig) projectsUsing(url string) map[string]string {
+	projects := map[string]string{}
+	for _, dir := range r.ZettenProjects {
+		data, err := os.ReadFile(filepath.Join(dir, "zetten.yml"))
+		if err != nil {
+			continue
+		}
+		var project struct {
+			Dependencies map[string]string `yaml:"dependencies"`
+		}
+		if err := yaml.Unmarshal(data, &project); err != nil {
+			continue
+		}
+		if version, ok := project.Dependencies[url]; ok {
+			projects[dir] = version
+		}
+	}
+	return projects
+}
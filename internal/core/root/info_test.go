@@ -0,0 +1,42 @@
+package root_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfo(t *testing.T) {
+	projectDir := t.TempDir()
+	r := &root.RootConfig{RootFile: root.RootFile{ZettenProjects: []string{projectDir}}}
+	url := "https://example.com/org/info.git"
+	repo := initCachedPackage(t, r, url, "v1.0.0")
+
+	releaseUpstream(t, repo, "v1.1.0", map[string]string{
+		"zetten-package.yml": "repository: example.com/org/info\ndescription: Info package\nlicense: MIT\ndependencies:\n  https://example.com/org/dep.git: v2.0.0\n",
+	})
+	head, err := repo.Head()
+	require.NoError(t, err)
+	tagger := &object.Signature{Name: "Release", Email: "release@example.com", When: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	_, err = repo.CreateTag("v1.2.0", head.Hash(), &git.CreateTagOptions{Tagger: tagger, Message: "Release v1.2.0"})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "zetten.yml"), []byte("name: app\ndependencies:\n  "+url+": v1.1.0\n"), 0644))
+
+	info, err := r.Info(url)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0", info.Latest)
+	require.NotNil(t, info.Manifest)
+	assert.Equal(t, "MIT", info.Manifest.License)
+	assert.Equal(t, map[string]string{"https://example.com/org/dep.git": "v2.0.0"}, info.Manifest.Dependencies)
+	assert.Equal(t, "master", info.DefaultBranch)
+	require.Len(t, info.Tags, 3)
+	assert.Equal(t, map[string]string{projectDir: "v1.1.0"}, info.Projects)
+}
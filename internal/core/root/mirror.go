@@ -0,0 +1,105 @@
+package root
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// MirrorPolicy decides which copy wins when a file changes in more than one
+// root of a mirror group.
+type MirrorPolicy string
+
+const (
+	// NewestWins keeps the copy modified last.
+	NewestWins MirrorPolicy = "newest-wins"
+	// SourceOfTruth keeps the copy of the group Source.
+	SourceOfTruth MirrorPolicy = "source-of-truth"
+	// KeepBoth keeps the newest copy and saves the other ones next to it as
+	// conflict copies.
+	KeepBoth MirrorPolicy = "keep-both"
+)
+
+// MirrorGroup is a set of directories kept identical by the mirror service.
+// A plain list of paths is a group with the default policy.
+type MirrorGroup struct {
+	Paths  []string     `yaml:"paths"`
+	Policy MirrorPolicy `yaml:"policy,omitempty"`
+	// Source is the path that wins conflicts with the source-of-truth policy
+	Source string `yaml:"source,omitempty"`
+	// Debounce is how long a path must stay quiet before its changes are
+	// propagated, DEFAULT_MIRROR_DEBOUNCE when zero
+	Debounce time.Duration `yaml:"debounce,omitempty"`
+	// Ignore lists gitignore style patterns of paths left out of the group
+	Ignore []string `yaml:"ignore,omitempty"`
+	// GitIgnore also leaves out the paths ignored by the .gitignore files of
+	// the roots
+	GitIgnore bool `yaml:"gitignore,omitempty"`
+}
+
+const DEFAULT_MIRROR_DEBOUNCE = 300 * time.Millisecond
+
+type mirrorGroup MirrorGroup
+
+func (g *MirrorGroup) UnmarshalYAML(data []byte) error {
+	var paths []string
+	if err := yaml.Unmarshal(data, &paths); err == nil {
+		*g = MirrorGroup{Paths: paths}
+		return nil
+	}
+	var group mirrorGroup
+	if err := yaml.Unmarshal(data, &group); err != nil {
+		return err
+	}
+	// invalid groups are skipped by the mirror service rather than failing
+	// every command that loads the root config
+	*g = MirrorGroup(group)
+	return nil
+}
+
+func (g MirrorGroup) MarshalYAML() (interface{}, error) {
+	if g.Policy == "" && g.Source == "" && g.Debounce == 0 && len(g.Ignore) == 0 && !g.GitIgnore {
+		return g.Paths, nil
+	}
+	return mirrorGroup(g), nil
+}
+
+// ConflictPolicy returns the policy of the group, newest-wins by default.
+func (g MirrorGroup) ConflictPolicy() MirrorPolicy {
+	if g.Policy == "" {
+		return NewestWins
+	}
+	return g.Policy
+}
+
+// WithIgnore returns the group with global patterns added before its own.
+func (g MirrorGroup) WithIgnore(global []string) MirrorGroup {
+	g.Ignore = append(slices.Clone(global), g.Ignore...)
+	return g
+}
+
+// QuietPeriod returns the debounce of the group.
+func (g MirrorGroup) QuietPeriod() time.Duration {
+	if g.Debounce <= 0 {
+		return DEFAULT_MIRROR_DEBOUNCE
+	}
+	return g.Debounce
+}
+
+func (g MirrorGroup) Validate() error {
+	switch g.ConflictPolicy() {
+	case NewestWins, KeepBoth:
+		return nil
+	case SourceOfTruth:
+		for _, path := range g.Paths {
+			if path == g.Source {
+				return nil
+			}
+		}
+		return fmt.Errorf("source-of-truth mirror group needs a source among its paths %v, got %q", g.Paths, g.Source)
+	default:
+		return fmt.Errorf("unknown mirror policy %q", g.Policy)
+	}
+}
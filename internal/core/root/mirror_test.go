@@ -0,0 +1,59 @@
+package root_test
+
+import (
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorGroup_YAML(t *testing.T) {
+	var file root.RootFile
+	require.NoError(t, yaml.Unmarshal([]byte(`
+mirror:
+  - [/a, /b]
+  - paths: [/c, /d]
+    policy: source-of-truth
+    source: /c
+`), &file))
+
+	require.Len(t, file.Mirror, 2)
+	assert.Equal(t, root.MirrorGroup{Paths: []string{"/a", "/b"}}, file.Mirror[0])
+	assert.Equal(t, root.NewestWins, file.Mirror[0].ConflictPolicy())
+	assert.Equal(t, root.MirrorGroup{Paths: []string{"/c", "/d"}, Policy: root.SourceOfTruth, Source: "/c"}, file.Mirror[1])
+
+	data, err := yaml.Marshal(file.Mirror)
+	require.NoError(t, err)
+	var roundTrip []root.MirrorGroup
+	require.NoError(t, yaml.Unmarshal(data, &roundTrip))
+	assert.Equal(t, file.Mirror, roundTrip)
+}
+
+func TestMirrorGroup_Validate(t *testing.T) {
+	assert.NoError(t, root.MirrorGroup{Paths: []string{"/a"}, Policy: root.KeepBoth}.Validate())
+	assert.Error(t, root.MirrorGroup{Paths: []string{"/a"}, Policy: root.SourceOfTruth, Source: "/b"}.Validate())
+	assert.Error(t, root.MirrorGroup{Paths: []string{"/a"}, Policy: "last-write"}.Validate())
+}
+
+func TestMirrorGroup_InvalidGroupStillLoads(t *testing.T) {
+	var file root.RootFile
+	require.NoError(t, yaml.Unmarshal([]byte(`
+mirror:
+  - paths: [/a, /b]
+    policy: newest
+`), &file))
+
+	require.Len(t, file.Mirror, 1)
+	assert.Error(t, file.Mirror[0].Validate())
+}
+
+func TestMirrorGroup_WithIgnore(t *testing.T) {
+	group := root.MirrorGroup{Paths: []string{"/a"}, Ignore: []string{"dist/"}}
+	global := []string{".git/"}
+
+	assert.Equal(t, []string{".git/", "dist/"}, group.WithIgnore(global).Ignore)
+	assert.Equal(t, []string{"dist/"}, group.Ignore)
+	assert.Equal(t, []string{".git/"}, global)
+}
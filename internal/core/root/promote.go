@@ -0,0 +1,222 @@
+package root
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const DEFAULT_COMMIT_TEMPLATE = `{{if .Message}}{{.Message}}{{else}}Promote {{.Package}} to {{.Tag}}{{end}}
+
+Project: {{.Project}} {{.ProjectVersion}}
+Base: {{.BaseTag}}
+
+Changed files:
+{{range .Files}}- {{.}}
+{{end}}`
+
+const DEFAULT_TAG_TEMPLATE = `Release {{.Tag}}
+
+Promoted from {{.BaseTag}} by {{.Project}} {{.ProjectVersion}}
+{{if .Changelog}}
+{{.Changelog}}{{end}}`
+
+type Author struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+}
+
+// SigningConfig selects how promotion commits and tags are signed.
+// Format is "gpg" or "ssh"; an empty format disables signing.
+type SigningConfig struct {
+	Format  string `yaml:"format"`
+	Key     string `yaml:"key"`
+	Program string `yaml:"program,omitempty"`
+}
+
+type PromotionConfig struct {
+	Author         Author          `yaml:"author,omitempty"`
+	CommitTemplate string          `yaml:"commitTemplate,omitempty"`
+	TagTemplate    string          `yaml:"tagTemplate,omitempty"`
+	Signing        SigningConfig   `yaml:"signing,omitempty"`
+	Changelog      ChangelogConfig `yaml:"changelog,omitempty"`
+}
+
+type PromoteOptions struct {
+	ProjectName    string
+	ProjectVersion string
+	Message        string
+	Strategy       PromoteStrategy
+	Push           bool
+	// Keep lists the patterns of local-only paths of the package, which are
+	// never promoted nor removed from it
+	Keep []string
+}
+
+type PromoteOpt func(*PromoteOptions)
+
+func WithProject(name, version string) PromoteOpt {
+	return func(o *PromoteOptions) {
+		o.ProjectName = name
+		o.ProjectVersion = version
+	}
+}
+
+// WithMessage sets the summary of the promotion, used as the commit subject
+// and, when it follows the conventional-commit format, as a changelog entry.
+func WithMessage(message string) PromoteOpt {
+	return func(o *PromoteOptions) {
+		o.Message = message
+	}
+}
+
+// WithPush publishes the new tag through the source of the package once
+// promoted.
+func WithPush() PromoteOpt {
+	return func(o *PromoteOptions) {
+		o.Push = true
+	}
+}
+
+// skip reports whether the slash separated path rel of the package is left
+// out of the promotion: the git directory and the kept paths.
+func (o *PromoteOptions) skip(rel string) bool {
+	return path.Base(rel) == ".git" || util.MatchPaths(o.Keep, rel)
+}
+
+// WithKeep leaves the paths matching patterns, such as the Keep entries of
+// the project, out of the promotion.
+func WithKeep(patterns ...string) PromoteOpt {
+	return func(o *PromoteOptions) {
+		o.Keep = patterns
+	}
+}
+
+// WithStrategy selects how local edits are reconciled with versions released
+// upstream after the base version.
+func WithStrategy(strategy PromoteStrategy) PromoteOpt {
+	return func(o *PromoteOptions) {
+		o.Strategy = strategy
+	}
+}
+
+// PromoteMessageData is the data available to commit and tag templates.
+type PromoteMessageData struct {
+	Package        string
+	Project        string
+	ProjectVersion string
+	BaseTag        string
+	Tag            string
+	Message        string
+	Files          []string
+	Changelog      string
+}
+
+func renderTemplate(name, text, fallback string, data PromoteMessageData) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = fallback
+	}
+	tpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()) + "\n", nil
+}
+
+// resolveAuthor returns the configured zetten author, falling back to the
+// user.name and user.email of the repository, global and system git config.
+func (c *PromotionConfig) resolveAuthor(repo *git.Repository) (*object.Signature, error) {
+	name, email := c.Author.Name, c.Author.Email
+	if name == "" || email == "" {
+		cfg, err := repo.ConfigScoped(gitconfig.SystemScope)
+		if err != nil {
+			return nil, err
+		}
+		name = util.Or(name, cfg.User.Name)
+		email = util.Or(email, cfg.User.Email)
+	}
+	if name == "" || email == "" {
+		return nil, ErrMissingAuthor
+	}
+	return &object.Signature{Name: name, Email: email, When: time.Now()}, nil
+}
+
+func changedFiles(wt *git.Worktree) ([]string, error) {
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for path, s := range status {
+		if s.Staging != git.Unmodified && s.Staging != git.Untracked {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// createAnnotatedTag stores an annotated tag object for hash, signing it when
+// signer is not nil, and points refs/tags/<name> at it.
+func createAnnotatedTag(repo *git.Repository, name string, hash plumbing.Hash, tagger *object.Signature, message string, signer git.Signer) error {
+	if _, err := repo.Tag(name); err == nil {
+		return fmt.Errorf("%w: %s", ErrTagExists, name)
+	}
+
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *tagger,
+		Message:    message,
+		TargetType: plumbing.CommitObject,
+		Target:     hash,
+	}
+
+	if signer != nil {
+		encoded := &plumbing.MemoryObject{}
+		if err := tag.EncodeWithoutSignature(encoded); err != nil {
+			return err
+		}
+		reader, err := encoded.Reader()
+		if err != nil {
+			return err
+		}
+		sig, err := signer.Sign(reader)
+		if err != nil {
+			return fmt.Errorf("failed to sign tag %s: %w", name, err)
+		}
+		tag.PGPSignature = string(sig)
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := tag.Encode(obj); err != nil {
+		return err
+	}
+	tagHash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return err
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), tagHash))
+}
+
+// resolveCommit resolves a tag, branch or hash to the commit it points at.
+func resolveCommit(repo *git.Repository, rev string) (plumbing.Hash, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve %s: %w", rev, err)
+	}
+	return *hash, nil
+}
@@ -0,0 +1,276 @@
+package root_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initCachedPackage creates a git repository at the root cache path of url
+// with a single commit tagged as tag.
+func initCachedPackage(t *testing.T, r *root.RootConfig, url, tag string) *git.Repository {
+	t.Helper()
+	originalPackagesPath := root.DEFAULT_ROOT_PACKAGES_PATH
+	root.DEFAULT_ROOT_PACKAGES_PATH = t.TempDir()
+	t.Cleanup(func() { root.DEFAULT_ROOT_PACKAGES_PATH = originalPackagesPath })
+
+	dir := r.BuildRootPackagePath(url)
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add("main.go")
+	require.NoError(t, err)
+	sig := &object.Signature{Name: "Upstream", Email: "upstream@example.com", When: time.Now()}
+	hash, err := wt.Commit("initial", &git.CommitOptions{Author: sig})
+	require.NoError(t, err)
+	_, err = repo.CreateTag(tag, hash, nil)
+	require.NoError(t, err)
+	return repo
+}
+
+func TestPromote_AnnotatedTagWithAuthorAndTemplate(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author:         root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+		CommitTemplate: "{{.Project}}@{{.ProjectVersion}} promotes {{.Tag}}\n{{range .Files}}{{.}};{{end}}",
+	}}}
+	url := "https://example.com/org/lib.git"
+	repo := initCachedPackage(t, r, url, "v1.0.0")
+
+	packageDir := t.TempDir()
+	os.WriteFile(filepath.Join(packageDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(packageDir, "util.go"), []byte("package main\n"), 0644)
+
+	err := r.Promote(url, "v1.0.0", "v1.1.0", packageDir, root.WithProject("app", "2.0.0"))
+	require.NoError(t, err)
+
+	ref, err := repo.Tag("v1.1.0")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err, "tag should be annotated")
+	assert.Equal(t, "jane@example.com", tag.Tagger.Email)
+	assert.Contains(t, tag.Message, "v1.1.0")
+
+	commit, err := tag.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", commit.Author.Name)
+	assert.Equal(t, "app@2.0.0 promotes v1.1.0\nCHANGELOG.md;main.go;util.go;\n", commit.Message)
+}
+
+func TestPromote_InvalidSigningFormat(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author:  root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+		Signing: root.SigningConfig{Format: "x509"},
+	}}}
+	url := "https://example.com/org/signed.git"
+	initCachedPackage(t, r, url, "v1.0.0")
+
+	err := r.Promote(url, "v1.0.0", "v1.0.1", t.TempDir())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid signing format")
+}
+
+func TestPromote_GeneratesChangelog(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author: root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+	}}}
+	url := "https://example.com/org/changelog.git"
+	repo := initCachedPackage(t, r, url, "v1.0.0")
+
+	packageDir := t.TempDir()
+	os.WriteFile(filepath.Join(packageDir, "main.go"), []byte("package main\n// v1.1.0\n"), 0644)
+	err := r.Promote(url, "v1.0.0", "v1.1.0", packageDir, root.WithMessage("feat(ui): add button"))
+	require.NoError(t, err)
+
+	os.WriteFile(filepath.Join(packageDir, "main.go"), []byte("package main\n// v1.2.0\n"), 0644)
+	err = r.Promote(url, "v1.1.0", "v1.2.0", packageDir, root.WithMessage("fix!: drop legacy flag"))
+	require.NoError(t, err)
+
+	changelog, err := os.ReadFile(filepath.Join(packageDir, "CHANGELOG.md"))
+	require.NoError(t, err)
+	content := string(changelog)
+	assert.True(t, strings.HasPrefix(content, "# Changelog\n\n## v1.2.0 ("))
+	assert.Contains(t, content, "### ⚠ BREAKING CHANGES\n\n- drop legacy flag")
+	assert.Contains(t, content, "### Features\n\n- **ui:** add button")
+	assert.Less(t, strings.Index(content, "## v1.2.0"), strings.Index(content, "## v1.1.0"))
+
+	ref, err := repo.Tag("v1.2.0")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	assert.Contains(t, tag.Message, "### Bug Fixes\n\n- drop legacy flag")
+
+	commit, err := tag.Commit()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(commit.Message, "fix!: drop legacy flag\n"))
+}
+
+// releaseUpstream commits files on top of the cached package HEAD and tags
+// the commit, as if another developer had promoted it.
+func releaseUpstream(t *testing.T, repo *git.Repository, tag string, files map[string]string) {
+	t.Helper()
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(wt.Filesystem.Root(), name), []byte(content), 0644))
+	}
+	require.NoError(t, wt.AddWithOptions(&git.AddOptions{All: true}))
+	sig := &object.Signature{Name: "Upstream", Email: "upstream@example.com", When: time.Now()}
+	hash, err := wt.Commit("upstream "+tag, &git.CommitOptions{Author: sig})
+	require.NoError(t, err)
+	_, err = repo.CreateTag(tag, hash, nil)
+	require.NoError(t, err)
+}
+
+func TestPromote_RefusesExistingOrLowerTag(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author: root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+	}}}
+	url := "https://example.com/org/guard.git"
+	initCachedPackage(t, r, url, "v1.2.0")
+
+	err := r.Promote(url, "v1.2.0", "v1.2.0", t.TempDir())
+	assert.ErrorIs(t, err, root.ErrTagExists)
+
+	err = r.Promote(url, "v1.2.0", "v1.1.9", t.TempDir())
+	assert.ErrorIs(t, err, root.ErrVersionNotGreater)
+}
+
+func TestPromote_UpstreamChanged(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author: root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+	}}}
+	url := "https://example.com/org/upstream.git"
+	repo := initCachedPackage(t, r, url, "v1.2.0")
+	releaseUpstream(t, repo, "v1.3.0", map[string]string{"upstream.go": "package main\n"})
+
+	packageDir := t.TempDir()
+	os.WriteFile(filepath.Join(packageDir, "main.go"), []byte("package main\n\n// local\n"), 0644)
+
+	err := r.Promote(url, "v1.2.0", "v1.3.1", packageDir)
+	assert.ErrorIs(t, err, root.ErrUpstreamChanged)
+
+	err = r.Promote(url, "v1.2.0", "v1.3.0-local", packageDir, root.WithStrategy(root.RebaseStrategy))
+	assert.ErrorIs(t, err, root.ErrVersionNotGreater)
+
+	err = r.Promote(url, "v1.2.0", "v1.3.1", packageDir, root.WithStrategy(root.RebaseStrategy))
+	require.NoError(t, err)
+
+	ref, err := repo.Tag("v1.3.1")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	commit, err := tag.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, 1, commit.NumParents())
+	parent, err := commit.Parent(0)
+	require.NoError(t, err)
+	assert.Equal(t, "upstream v1.3.0", parent.Message)
+
+	assert.FileExists(t, filepath.Join(packageDir, "upstream.go"))
+	content, _ := os.ReadFile(filepath.Join(packageDir, "main.go"))
+	assert.Equal(t, "package main\n\n// local\n", string(content))
+}
+
+func TestPromote_MergeStrategy(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author: root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+	}}}
+	url := "https://example.com/org/merge.git"
+	repo := initCachedPackage(t, r, url, "v1.2.0")
+	releaseUpstream(t, repo, "v1.3.0", map[string]string{"main.go": "package main\n\nfunc upstream() {}\n"})
+
+	packageDir := t.TempDir()
+	os.WriteFile(filepath.Join(packageDir, "main.go"), []byte("package main\n\nfunc local() {}\n"), 0644)
+
+	err := r.Promote(url, "v1.2.0", "v1.4.0", packageDir, root.WithStrategy(root.MergeStrategy))
+	var conflict *root.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{"main.go"}, conflict.Files)
+	assert.Equal(t, "v1.3.0", conflict.Base)
+	_, err = repo.Tag("v1.4.0")
+	assert.Error(t, err)
+
+	content, _ := os.ReadFile(filepath.Join(packageDir, "main.go"))
+	assert.Contains(t, string(content), "<<<<<<< local")
+	os.WriteFile(filepath.Join(packageDir, "main.go"), []byte("package main\n\nfunc upstream() {}\n\nfunc local() {}\n"), 0644)
+
+	err = r.Promote(url, conflict.Base, "v1.4.0", packageDir)
+	require.NoError(t, err)
+	_, err = repo.Tag("v1.4.0")
+	assert.NoError(t, err)
+}
+
+func TestPromote_MergeCommit(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author: root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+	}}}
+	url := "https://example.com/org/merge-commit.git"
+	repo := initCachedPackage(t, r, url, "v1.2.0")
+	releaseUpstream(t, repo, "v1.3.0", map[string]string{"upstream.go": "package main\n"})
+
+	packageDir := t.TempDir()
+	os.WriteFile(filepath.Join(packageDir, "main.go"), []byte("package main\n\n// local\n"), 0644)
+
+	err := r.Promote(url, "v1.2.0", "v1.4.0", packageDir, root.WithStrategy(root.MergeStrategy))
+	require.NoError(t, err)
+
+	ref, err := repo.Tag("v1.4.0")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	commit, err := tag.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, 2, commit.NumParents())
+
+	// the upstream release and the local changes are listed after the
+	// promotion itself
+	assert.Contains(t, tag.Message, "### Other Changes\n\n- Promote to tag v1.4.0\n- ")
+	assert.Contains(t, tag.Message, "- upstream v1.3.0 (")
+	assert.Contains(t, tag.Message, "- Local changes on top of v1.2.0 (")
+	assert.NotContains(t, tag.Message, "- initial")
+}
+
+func TestPromote_DeletedAndKeptFiles(t *testing.T) {
+	r := &root.RootConfig{RootFile: root.RootFile{Promotion: root.PromotionConfig{
+		Author: root.Author{Name: "Jane Doe", Email: "jane@example.com"},
+	}}}
+	url := "https://example.com/org/kept.git"
+	repo := initCachedPackage(t, r, url, "v1.0.0")
+
+	// main.go was deleted locally, local.env only lives in the project
+	packageDir := t.TempDir()
+	os.WriteFile(filepath.Join(packageDir, "util.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(packageDir, "local.env"), []byte("TOKEN=secret\n"), 0644)
+
+	err := r.Promote(url, "v1.0.0", "v1.1.0", packageDir, root.WithKeep("*.env"))
+	require.NoError(t, err)
+
+	ref, err := repo.Tag("v1.1.0")
+	require.NoError(t, err)
+	tag, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err)
+	commit, err := tag.Commit()
+	require.NoError(t, err)
+	tree, err := commit.Tree()
+	require.NoError(t, err)
+	_, err = tree.File("main.go")
+	assert.Error(t, err, "deleted file should not be promoted")
+	_, err = tree.File("local.env")
+	assert.Error(t, err, "kept file should not be promoted")
+	_, err = tree.File("util.go")
+	assert.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(packageDir, "main.go"))
+	assert.FileExists(t, filepath.Join(packageDir, "local.env"))
+}
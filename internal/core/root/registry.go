@@ -0,0 +1,154 @@
+package root
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/goccy/go-yaml"
+)
+
+// RegistryEntry maps a short package name to its url.
+type RegistryEntry struct {
+	Name        string   `yaml:"name"`
+	Url         string   `yaml:"url"`
+	Description string   `yaml:"description,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+}
+
+// RegistryIndex is the content of a registry file.
+type RegistryIndex struct {
+	Packages []RegistryEntry `yaml:"packages"`
+}
+
+// LoadRegistry reads every configured registry, a local YAML file or an
+// http(s) url serving one. When several list the same name the first
+// registry wins.
+func (r *RootConfig) LoadRegistry() ([]RegistryEntry, error) {
+	seen := map[string]bool{}
+	var entries []RegistryEntry
+	for _, location := range r.Registries {
+		index, err := readRegistry(location)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range index.Packages {
+			if entry.Name == "" || entry.Url == "" || seen[entry.Name] {
+				continue
+			}
+			seen[entry.Name] = true
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+func readRegistry(location string) (*RegistryIndex, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		data, err = fetchRegistry(location)
+	} else {
+		data, err = os.ReadFile(strings.TrimPrefix(location, "file://"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry %s: %w", location, err)
+	}
+	var index RegistryIndex
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse registry %s: %w", location, err)
+	}
+	return &index, nil
+}
+
+func fetchRegistry(location string) ([]byte, error) {
+	resp, err := http.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// LookupPackage returns the registry entry named name.
+func (r *RootConfig) LookupPackage(name string) (*RegistryEntry, error) {
+	entries, err := r.LoadRegistry()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Name == name {
+			return &entry, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+}
+
+// SearchPackages returns the registry entries whose name, description or
+// tags contain term, ignoring case.
+func (r *RootConfig) SearchPackages(term string) ([]RegistryEntry, error) {
+	entries, err := r.LoadRegistry()
+	if err != nil {
+		return nil, err
+	}
+	term = strings.ToLower(term)
+	var found []RegistryEntry
+	for _, entry := range entries {
+		if entry.matches(term) {
+			found = append(found, entry)
+		}
+	}
+	return found, nil
+}
+
+func (e *RegistryEntry) matches(term string) bool {
+	if strings.Contains(strings.ToLower(e.Name), term) || strings.Contains(strings.ToLower(e.Description), term) {
+		return true
+	}
+	for _, tag := range e.Tags {
+		if strings.Contains(strings.ToLower(tag), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePackage resolves a registry reference such as ui-kit or ui-kit@^2 to
+// the url of the package and, when a constraint is given, its highest
+// version satisfying it.
+func (r *RootConfig) ResolvePackage(ref string) (string, string, error) {
+	name, spec, _ := strings.Cut(ref, "@")
+	entry, err := r.LookupPackage(name)
+	if err != nil {
+		return "", "", err
+	}
+	if spec == "" {
+		return entry.Url, "", nil
+	}
+
+	versions, err := r.Versions(entry.Url)
+	if err != nil {
+		return "", "", err
+	}
+	if slices.Contains(versions, spec) {
+		return entry.Url, spec, nil
+	}
+	constraint, err := util.ParseConstraint(spec)
+	if err != nil {
+		return "", "", err
+	}
+	version, ok := constraint.MaxSatisfying(versions)
+	if !ok {
+		return "", "", fmt.Errorf("no version of %s satisfies %s", name, spec)
+	}
+	return entry.Url, version, nil
+}
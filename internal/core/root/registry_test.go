@@ -0,0 +1,84 @@
+package root_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const localRegistry = `packages:
+  - name: ui-kit
+    url: mem://ui-kit
+    description: Shared UI components
+    tags: [frontend, react]
+  - name: logger
+    url: https://example.com/org/logger.git
+    description: Structured logging
+`
+
+const remoteRegistry = `packages:
+  - name: ui-kit
+    url: https://example.com/shadowed.git
+  - name: auth
+    url: https://example.com/org/auth.git
+    tags: [security]
+`
+
+func newRegistryRoot(t *testing.T) *root.RootConfig {
+	path := filepath.Join(t.TempDir(), "registry.yml")
+	require.NoError(t, os.WriteFile(path, []byte(localRegistry), 0644))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(remoteRegistry))
+	}))
+	t.Cleanup(server.Close)
+
+	r := &root.RootConfig{RootFile: root.RootFile{Registries: []string{path, server.URL + "/index.yml"}}}
+	r.RegisterSource("mem", func(url string) (root.Source, error) {
+		return memorySource{"v1.4.0": nil, "v2.0.0": nil, "v2.3.1": nil, "v3.0.0": nil}, nil
+	})
+	return r
+}
+
+func TestSearchPackages(t *testing.T) {
+	r := newRegistryRoot(t)
+
+	entries, err := r.SearchPackages("")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "mem://ui-kit", entries[2].Url)
+
+	entries, err = r.SearchPackages("SECURITY")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "auth", entries[0].Name)
+
+	entries, err = r.SearchPackages("components")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ui-kit", entries[0].Name)
+}
+
+func TestResolvePackage(t *testing.T) {
+	r := newRegistryRoot(t)
+
+	url, version, err := r.ResolvePackage("ui-kit@^2")
+	require.NoError(t, err)
+	assert.Equal(t, "mem://ui-kit", url)
+	assert.Equal(t, "v2.3.1", version)
+
+	url, version, err = r.ResolvePackage("auth")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/org/auth.git", url)
+	assert.Empty(t, version)
+
+	_, _, err = r.ResolvePackage("ui-kit@^4")
+	assert.Error(t, err)
+	_, _, err = r.ResolvePackage("missing")
+	assert.ErrorIs(t, err, root.ErrPackageNotFound)
+}
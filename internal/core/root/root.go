@@ -5,11 +5,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/core-stack/zetten-cli/internal/auth"
 	"github.com/core-stack/zetten-cli/internal/core/file"
 	"github.com/core-stack/zetten-cli/internal/util"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 var home, err = os.UserHomeDir()
@@ -17,16 +20,22 @@ var DEFAULT_ROOT_PATH = filepath.Join(home, ".zetten")
 var DEFAULT_ROOT_CONFIG_PATH = filepath.Join(DEFAULT_ROOT_PATH, "config.yml")
 var DEFAULT_ROOT_PACKAGES_PATH = filepath.Join(DEFAULT_ROOT_PATH, "packages")
 
+// IRootConfig is what projects need from the root, independent of the
+// Source packages come from.
 type IRootConfig interface {
-	BuildRootPackagePath(url string) string
-	HasPackage(url string) bool
-	OpenOrClonePackage(url string) (*git.Repository, error)
-	Checkout(url, tag string) (*git.Repository, error)
-	CopyRootFiles(url, destination string, ignore []string) error
-	Promote(url, tag, newTag, packageDir string) error
+	Versions(url string) ([]string, error)
+	Resolve(url, version string) (string, error)
+	CopyRootFiles(url, tag, destination string, ignore []string) error
+	ReadPackageFiles(url, tag string) (map[string][]byte, error)
+	Record(url string, files map[string][]byte) (string, error)
+	Snapshot(url, tag string) (string, error)
+	Promote(url, tag, newTag, packageDir string, opts ...PromoteOpt) error
+	AddProject(dir string, autoSave bool) error
 }
 type RootConfig struct {
 	RootFile `yaml:",inline"`
+
+	sources map[string]SourceFunc
 }
 
 func (r *RootConfig) BuildRootPackagePath(url string) string {
@@ -39,13 +48,21 @@ func (r *RootConfig) HasPackage(url string) bool {
 }
 
 func (r *RootConfig) OpenOrClonePackage(url string) (*git.Repository, error) {
+	if util.IsArchive(url) {
+		return nil, fmt.Errorf("%w: %s is an archive", ErrNotGitPackage, url)
+	}
 	destination := r.BuildRootPackagePath(url)
 	if r.HasPackage(url) {
 		return git.PlainOpen(destination)
 	}
 
+	cloneUrl, method, err := auth.ResolveAuth(url)
+	if err != nil {
+		return nil, err
+	}
 	repo, err := git.PlainClone(destination, false, &git.CloneOptions{
-		URL:      url,
+		URL:      cloneUrl,
+		Auth:     method,
 		Progress: os.Stdout,
 	})
 	if err != nil {
@@ -69,53 +86,268 @@ func (r *RootConfig) Checkout(url, tag string) (*git.Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = wt.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(tag)})
+	hash, err := resolveCommit(repo, tag)
+	if err != nil {
+		return nil, err
+	}
+	err = wt.Checkout(&git.CheckoutOptions{Hash: hash})
 	if err != nil {
 		return nil, err
 	}
 	return repo, nil
 }
 
-func (r *RootConfig) Promote(url, baseTag, newTag, packageDir string) error {
-	repo, err := r.Checkout(url, baseTag)
+func (r *RootConfig) Promote(url, baseTag, newTag, packageDir string, opts ...PromoteOpt) error {
+	options := &PromoteOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	repo, err := r.OpenOrClonePackage(url)
+	if err != nil {
+		return err
+	}
+	fetchUpstream(repo, url)
+	latestTag, err := checkPromotion(repo, baseTag, newTag)
+	if err != nil {
+		return err
+	}
+
+	author, err := r.Promotion.resolveAuthor(repo)
+	if err != nil {
+		return err
+	}
+	signer, err := r.Promotion.Signing.NewSigner()
 	if err != nil {
 		return err
 	}
 
 	srcDir := r.BuildRootPackagePath(url)
-	err = util.CopyDir(packageDir, srcDir, []string{})
+	baseHash, err := resolveCommit(repo, baseTag)
 	if err != nil {
 		return err
 	}
+	var parents []plumbing.Hash
+	if latestTag == "" {
+		if _, err = r.Checkout(url, baseTag); err != nil {
+			return err
+		}
+		if err = util.SyncDirFunc(packageDir, srcDir, options.skip); err != nil {
+			return err
+		}
+	} else {
+		parents, err = r.prepareUpstreamMerge(repo, url, baseTag, latestTag, packageDir, options, author, signer)
+		if err != nil {
+			return err
+		}
+		baseTag = latestTag
+	}
 
 	wt, err := repo.Worktree()
 	if err != nil {
 		return err
 	}
 
+	data := PromoteMessageData{
+		Package:        url,
+		Project:        options.ProjectName,
+		ProjectVersion: options.ProjectVersion,
+		BaseTag:        baseTag,
+		Tag:            newTag,
+		Message:        options.Message,
+	}
+
+	changelogFile := r.Promotion.Changelog.fileName()
+	if !r.Promotion.Changelog.Disabled {
+		tips := parents
+		if len(tips) == 0 {
+			head, err := repo.Head()
+			if err != nil {
+				return err
+			}
+			tips = []plumbing.Hash{head.Hash()}
+		}
+		entries, err := collectChanges(repo, baseHash, tips, util.Or(options.Message, fmt.Sprintf("Promote to tag %s", newTag)))
+		if err != nil {
+			return err
+		}
+		data.Changelog = buildChangelogSection(newTag, time.Now(), entries)
+		if err := updateChangelog(filepath.Join(srcDir, changelogFile), newTag, data.Changelog); err != nil {
+			return fmt.Errorf("failed to update changelog: %w", err)
+		}
+	}
+
 	err = wt.AddWithOptions(&git.AddOptions{All: true})
 	if err != nil {
 		return err
 	}
 
-	commitHash, err := wt.Commit(fmt.Sprintf("Promote to tag %s", newTag), nil)
+	data.Files, err = changedFiles(wt)
+	if err != nil {
+		return err
+	}
+	commitMessage, err := renderTemplate("commit", r.Promotion.CommitTemplate, DEFAULT_COMMIT_TEMPLATE, data)
+	if err != nil {
+		return err
+	}
+	tagMessage, err := renderTemplate("tag", r.Promotion.TagTemplate, DEFAULT_TAG_TEMPLATE, data)
+	if err != nil {
+		return err
+	}
+
+	commitHash, err := wt.Commit(commitMessage, &git.CommitOptions{
+		Author:    author,
+		Committer: author,
+		Signer:    signer,
+		Parents:   parents,
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = repo.CreateTag(newTag, commitHash, nil)
+	err = createAnnotatedTag(repo, newTag, commitHash, author, tagMessage, signer)
 	if err != nil {
 		return err
 	}
 
+	// keep the installed copy in line with the promoted tag
+	if err := util.SyncDirFunc(srcDir, packageDir, options.skip); err != nil {
+		return err
+	}
+
 	fmt.Printf("✅ Changes promoted and tagged as %s\n", newTag)
+	if options.Push {
+		src, err := r.Source(url)
+		if err != nil {
+			return err
+		}
+		if err := src.Push(newTag); err != nil {
+			return err
+		}
+		fmt.Printf("🚀 Pushed %s\n", newTag)
+	}
 	return nil
 }
 
-func (r *RootConfig) CopyRootFiles(url, packagesDir string, ignore []string) error {
-	ignore = append(ignore, ".git")
+// prepareUpstreamMerge checks out latestTag in the package cache and applies
+// the local edits made on top of baseTag to it. With the merge strategy the
+// local edits are first committed on baseTag and the returned parents make
+// the promotion a merge commit.
+func (r *RootConfig) prepareUpstreamMerge(repo *git.Repository, url, baseTag, latestTag, packageDir string, options *PromoteOptions, author *object.Signature, signer git.Signer) ([]plumbing.Hash, error) {
+	strategy := options.Strategy
+	if strategy != RebaseStrategy && strategy != MergeStrategy {
+		return nil, fmt.Errorf("%w: %s was released after %s, promote with the merge or rebase strategy", ErrUpstreamChanged, latestTag, baseTag)
+	}
+	baseHash, err := resolveCommit(repo, baseTag)
+	if err != nil {
+		return nil, err
+	}
+	latestHash, err := resolveCommit(repo, latestTag)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, conflicts, err := mergePackage(repo, baseHash, latestHash, packageDir, latestTag, options.Keep)
+	if err != nil {
+		return nil, err
+	}
 	srcDir := r.BuildRootPackagePath(url)
-	return util.CopyDir(srcDir, packagesDir, ignore)
+	if len(conflicts) > 0 {
+		// leave latestTag plus the merged local edits in the package directory
+		if _, err := r.Checkout(url, latestTag); err != nil {
+			return nil, err
+		}
+		if err := util.SyncDirFunc(srcDir, packageDir, options.skip); err != nil {
+			return nil, err
+		}
+		if err := util.WriteMergedFiles(packageDir, merged); err != nil {
+			return nil, err
+		}
+		return nil, &ConflictError{Files: conflicts, Base: latestTag, Dir: packageDir}
+	}
+
+	var parents []plumbing.Hash
+	if strategy == MergeStrategy {
+		if _, err := r.Checkout(url, baseTag); err != nil {
+			return nil, err
+		}
+		if err := util.SyncDirFunc(packageDir, srcDir, options.skip); err != nil {
+			return nil, err
+		}
+		wt, err := repo.Worktree()
+		if err != nil {
+			return nil, err
+		}
+		if err := wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+			return nil, err
+		}
+		localHash, err := wt.Commit(fmt.Sprintf("Local changes on top of %s", baseTag), &git.CommitOptions{
+			Author:    author,
+			Committer: author,
+			Signer:    signer,
+		})
+		if err != nil {
+			return nil, err
+		}
+		parents = []plumbing.Hash{latestHash, localHash}
+	}
+
+	if _, err := r.Checkout(url, latestTag); err != nil {
+		return nil, err
+	}
+	if err := util.WriteMergedFiles(srcDir, merged); err != nil {
+		return nil, err
+	}
+	fmt.Printf("🔀 Applied local changes on top of %s (%s)\n", latestTag, strategy)
+	return parents, nil
+}
+
+// CopyRootFiles copies the package at tag from its store snapshot, never
+// from the worktree of the cache, so installs do not depend on what was
+// last checked out.
+func (r *RootConfig) CopyRootFiles(url, tag, packagesDir string, ignore []string) error {
+	snapshot, err := r.Snapshot(url, tag)
+	if err != nil {
+		return err
+	}
+	ignore = append(ignore, ".git")
+	return util.PlaceDir(snapshot, packagesDir, ignore, util.CopyFileWritable)
+}
+
+// ReadPackageFiles returns the files of the package at tag as exported by
+// its source, for git without touching the worktree of the cache. Checksum
+// revisions already in the store, such as recorded ones, are read from it.
+func (r *RootConfig) ReadPackageFiles(url, tag string) (map[string][]byte, error) {
+	if strings.HasPrefix(tag, CHECKSUM_PREFIX) {
+		store := r.Store()
+		snapshot, err := store.Get(SnapshotKey{Url: url, Commit: tag, Ignore: []string{".git"}})
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			return store.ReadFiles(snapshot)
+		}
+	}
+	src, err := r.Source(url)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := src.Resolve(tag)
+	if err != nil {
+		return nil, err
+	}
+	return src.Export(revision)
+}
+
+// Record stores files as a revision of url and returns it, for sources
+// whose content changes in place such as local directories. The revision
+// can be read back with ReadPackageFiles after url has changed.
+func (r *RootConfig) Record(url string, files map[string][]byte) (string, error) {
+	revision := CHECKSUM_PREFIX + filesDigest(files)
+	if _, err := r.Store().Put(SnapshotKey{Url: url, Commit: revision, Ignore: []string{".git"}}, files); err != nil {
+		return "", err
+	}
+	return revision, nil
 }
 
 func LoadRootConfig() (*RootConfig, error) {
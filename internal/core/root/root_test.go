@@ -49,21 +49,28 @@ func TestLoadRootConfig_CreatesConfig(t *testing.T) {
 }
 
 func TestCopyRootFiles(t *testing.T) {
+	originalStorePath := root.DEFAULT_ROOT_STORE_PATH
+	root.DEFAULT_ROOT_STORE_PATH = t.TempDir()
+	defer func() { root.DEFAULT_ROOT_STORE_PATH = originalStorePath }()
+
 	r := &root.RootConfig{}
 	tmpDst := t.TempDir()
-
-	// Simula estrutura de pacote clonado
 	url := "https://example.com/my/repo.git"
-	srcPath := r.BuildRootPackagePath(url)
-	os.MkdirAll(srcPath, 0755)
-	os.WriteFile(filepath.Join(srcPath, "main.go"), []byte("package main"), 0644)
-	os.WriteFile(filepath.Join(srcPath, ".git"), []byte("ignore me"), 0644)
+	initCachedPackage(t, r, url, "v1.0.0")
+
+	// o worktree do cache não é a fonte da instalação
+	os.WriteFile(filepath.Join(r.BuildRootPackagePath(url), "main.go"), []byte("edited"), 0644)
 
-	err := r.CopyRootFiles(url, tmpDst, []string{})
+	err := r.CopyRootFiles(url, "v1.0.0", tmpDst, []string{})
 	assert.NoError(t, err)
 
-	assert.FileExists(t, filepath.Join(tmpDst, "main.go"))
+	data, err := os.ReadFile(filepath.Join(tmpDst, "main.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
 	assert.NoFileExists(t, filepath.Join(tmpDst, ".git"))
+	info, err := os.Stat(filepath.Join(tmpDst, "main.go"))
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0200)
 }
 
 func TestOpenOrClonePackage(t *testing.T) {
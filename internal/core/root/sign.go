@@ -0,0 +1,63 @@
+package root
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// commandSigner signs git objects by piping them through an external program,
+// the same way git delegates to gpg.program and gpg.ssh.program.
+type commandSigner struct {
+	program string
+	args    []string
+}
+
+func (s *commandSigner) Sign(message io.Reader) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.program, s.args...)
+	cmd.Stdin = message
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", s.program, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// NewSigner builds a signer for the configured format, or returns nil when
+// signing is disabled.
+func (c *SigningConfig) NewSigner() (git.Signer, error) {
+	switch c.Format {
+	case "":
+		return nil, nil
+	case "gpg":
+		args := []string{"--detach-sign", "--armor"}
+		if c.Key != "" {
+			args = append(args, "--local-user", c.Key)
+		}
+		return &commandSigner{program: c.program("gpg"), args: args}, nil
+	case "ssh":
+		if c.Key == "" {
+			return nil, fmt.Errorf("ssh signing requires a key")
+		}
+		return &commandSigner{
+			program: c.program("ssh-keygen"),
+			args:    []string{"-Y", "sign", "-n", "git", "-f", os.ExpandEnv(c.Key)},
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid signing format: %s", c.Format)
+	}
+}
+
+func (c *SigningConfig) program(fallback string) string {
+	if c.Program != "" {
+		return c.Program
+	}
+	return fallback
+}
@@ -0,0 +1,54 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (r *RootConfig) Store() *Store {
+	return NewStore(DEFAULT_ROOT_STORE_PATH)
+}
+
+// Snapshot returns the read-only store directory holding the files of the
+// package at version, whatever its source. A stored snapshot that fails
+// verification is exported again from the source.
+func (r *RootConfig) Snapshot(url, version string) (string, error) {
+	src, err := r.Source(url)
+	if err != nil {
+		return "", err
+	}
+	revision, err := src.Resolve(version)
+	if err != nil {
+		return "", err
+	}
+
+	store := r.Store()
+	key := SnapshotKey{Url: url, Commit: revision, Ignore: []string{".git"}}
+	snapshot, err := store.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if snapshot != nil {
+		corrupt, err := store.Verify(snapshot)
+		if err != nil {
+			return "", err
+		}
+		if len(corrupt) == 0 {
+			return snapshot.Dir, nil
+		}
+		fmt.Printf("⚠️ Snapshot of %s@%s was modified (%s), rebuilding it\n", url, version, strings.Join(corrupt, ", "))
+		if err := store.Remove(snapshot, corrupt); err != nil {
+			return "", err
+		}
+	}
+
+	files, err := src.Export(revision)
+	if err != nil {
+		return "", err
+	}
+	snapshot, err = store.Put(key, files)
+	if err != nil {
+		return "", err
+	}
+	return snapshot.Dir, nil
+}
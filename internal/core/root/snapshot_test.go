@@ -0,0 +1,103 @@
+package root_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useTempStore(t *testing.T) {
+	originalStorePath := root.DEFAULT_ROOT_STORE_PATH
+	root.DEFAULT_ROOT_STORE_PATH = t.TempDir()
+	t.Cleanup(func() { root.DEFAULT_ROOT_STORE_PATH = originalStorePath })
+}
+
+func TestSnapshot_ReadOnlyAndShared(t *testing.T) {
+	useTempStore(t)
+	r := &root.RootConfig{}
+	url := "https://example.com/org/snap.git"
+	repo := initCachedPackage(t, r, url, "v1.0.0")
+
+	dir, err := r.Snapshot(url, "v1.0.0")
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+	info, err := os.Stat(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0444), info.Mode().Perm())
+
+	releaseUpstream(t, repo, "v1.1.0", map[string]string{"main.go": "package main\n// v1.1\n"})
+	again, err := r.Snapshot(url, "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+	next, err := r.Snapshot(url, "v1.1.0")
+	require.NoError(t, err)
+	assert.NotEqual(t, dir, next)
+
+	// a tag pointing at the same tree shares the snapshot
+	head, err := repo.Head()
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.1.1", head.Hash(), nil)
+	require.NoError(t, err)
+	same, err := r.Snapshot(url, "v1.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, next, same)
+}
+
+func TestSnapshot_RebuildsCorruptSnapshot(t *testing.T) {
+	useTempStore(t)
+	r := &root.RootConfig{}
+	url := "https://example.com/org/corrupt.git"
+	initCachedPackage(t, r, url, "v1.0.0")
+
+	dir, err := r.Snapshot(url, "v1.0.0")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.Chmod(path, 0644))
+	require.NoError(t, os.WriteFile(path, []byte("tampered\n"), 0644))
+
+	store := r.Store()
+	snapshots, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	corrupt, err := store.Verify(snapshots[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, corrupt)
+
+	dir, err = r.Snapshot(url, "v1.0.0")
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+}
+
+func TestStore_PutFiltersIgnore(t *testing.T) {
+	store := root.NewStore(t.TempDir())
+	key := root.SnapshotKey{Url: "u", Commit: "c", Ignore: []string{"testdata"}}
+
+	snapshot, err := store.Put(key, map[string][]byte{
+		"pkg/ui.go":            []byte("ui"),
+		"pkg/testdata/fixture": []byte("fixture"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pkg/ui.go"}, keys(snapshot.Files))
+
+	got, err := store.Get(key)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, snapshot.Digest, got.Digest)
+	assert.FileExists(t, filepath.Join(got.Dir, "pkg", "ui.go"))
+}
+
+func keys(m map[string]string) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
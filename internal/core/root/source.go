@@ -0,0 +1,256 @@
+package root
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/auth"
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// LOCAL_VERSION is the only version of a plain local directory: its current
+// content.
+const LOCAL_VERSION = "local"
+
+// Source is where the versions of a package come from.
+type Source interface {
+	// Versions lists the versions that can be installed, such as git tags.
+	Versions() ([]string, error)
+	// Resolve returns the immutable revision a version points to: a commit
+	// for git, a content checksum for archives and directories. An empty
+	// version resolves to the current revision where the source has one.
+	Resolve(version string) (string, error)
+	// Export returns the files of the package at a resolved revision.
+	Export(revision string) (map[string][]byte, error)
+	// Push publishes version upstream.
+	Push(version string) error
+}
+
+// SourceFunc opens the source of a package url.
+type SourceFunc func(url string) (Source, error)
+
+// RegisterSource makes urls with the given scheme, such as "s3" in
+// s3://bucket/ui, come from open instead of the built-in sources.
+func (r *RootConfig) RegisterSource(scheme string, open SourceFunc) {
+	if r.sources == nil {
+		r.sources = map[string]SourceFunc{}
+	}
+	r.sources[scheme] = open
+}
+
+// Source returns the source of url: a registered one for its scheme, else an
+// archive, a plain local directory or a git repository.
+func (r *RootConfig) Source(target string) (Source, error) {
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" {
+		if open, ok := r.sources[u.Scheme]; ok {
+			return open(target)
+		}
+	}
+	switch {
+	case util.IsArchive(target):
+		return &archiveSource{root: r, url: target}, nil
+	case util.IsLocalPath(target) && !isGitDir(strings.TrimPrefix(target, "file://")):
+		return &dirSource{dir: strings.TrimPrefix(target, "file://")}, nil
+	default:
+		return &gitSource{root: r, url: target}, nil
+	}
+}
+
+func isGitDir(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	// bare repositories
+	_, err := os.Stat(filepath.Join(dir, "HEAD"))
+	return err == nil
+}
+
+// Versions lists the versions of the package at url.
+func (r *RootConfig) Versions(url string) ([]string, error) {
+	src, err := r.Source(url)
+	if err != nil {
+		return nil, err
+	}
+	return src.Versions()
+}
+
+// Resolve returns the immutable revision version of url points to.
+func (r *RootConfig) Resolve(url, version string) (string, error) {
+	src, err := r.Source(url)
+	if err != nil {
+		return "", err
+	}
+	return src.Resolve(version)
+}
+
+type gitSource struct {
+	root *RootConfig
+	url  string
+}
+
+func (s *gitSource) Versions() ([]string, error) {
+	repo, err := s.root.OpenOrClonePackage(s.url)
+	if err != nil {
+		return nil, err
+	}
+	return listTags(repo)
+}
+
+// Resolve looks version up in the cache and, when it is missing, fetches
+// upstream once in case it was released since the clone.
+func (s *gitSource) Resolve(version string) (string, error) {
+	if version == "" {
+		return "", fmt.Errorf("tag is required for %s", s.url)
+	}
+	repo, err := s.root.OpenOrClonePackage(s.url)
+	if err != nil {
+		return "", err
+	}
+	hash, err := resolveCommit(repo, version)
+	if err != nil {
+		fetchUpstream(repo, s.url)
+		if hash, err = resolveCommit(repo, version); err != nil {
+			return "", fmt.Errorf("version %s of %s not found: %w", version, s.url, err)
+		}
+	}
+	return hash.String(), nil
+}
+
+func (s *gitSource) Export(revision string) (map[string][]byte, error) {
+	repo, err := s.root.OpenOrClonePackage(s.url)
+	if err != nil {
+		return nil, err
+	}
+	return treeFiles(repo, plumbing.NewHash(revision))
+}
+
+// Push pushes the tag version to origin.
+func (s *gitSource) Push(version string) error {
+	repo, err := s.root.OpenOrClonePackage(s.url)
+	if err != nil {
+		return err
+	}
+	_, method, err := auth.ResolveAuth(s.url)
+	if err != nil {
+		return err
+	}
+	ref := "refs/tags/" + version
+	err = repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		Auth:       method,
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(ref + ":" + ref)},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push %s to %s: %w", version, s.url, err)
+	}
+	return nil
+}
+
+type archiveSource struct {
+	root *RootConfig
+	url  string
+}
+
+// Versions returns the checksum of the archive as currently published.
+func (s *archiveSource) Versions() ([]string, error) {
+	checksum, err := s.Resolve("")
+	if err != nil {
+		return nil, err
+	}
+	return []string{checksum}, nil
+}
+
+// Resolve downloads the archive unless the store holds the pinned checksum,
+// and returns its checksum.
+func (s *archiveSource) Resolve(checksum string) (string, error) {
+	_, sum, err := s.read(checksum)
+	return sum, err
+}
+
+func (s *archiveSource) Export(checksum string) (map[string][]byte, error) {
+	data, _, err := s.read(checksum)
+	if err != nil {
+		return nil, err
+	}
+	files, err := util.ExtractArchive(util.ArchiveExtension(s.url), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %w", s.url, err)
+	}
+	return files, nil
+}
+
+func (s *archiveSource) read(checksum string) ([]byte, string, error) {
+	if checksum != "" && !strings.HasPrefix(checksum, CHECKSUM_PREFIX) {
+		return nil, "", fmt.Errorf("invalid checksum %q for %s, expected %s<hex>", checksum, s.url, CHECKSUM_PREFIX)
+	}
+	data, err := s.root.readArchive(s.url, strings.TrimPrefix(checksum, CHECKSUM_PREFIX))
+	if err != nil {
+		return nil, "", err
+	}
+	hash, err := s.root.Store().PutObject(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if checksum != "" && CHECKSUM_PREFIX+hash != checksum {
+		return nil, "", fmt.Errorf("%w: %s is %s%s, pinned %s", ErrChecksumMismatch, s.url, CHECKSUM_PREFIX, hash, checksum)
+	}
+	return data, CHECKSUM_PREFIX + hash, nil
+}
+
+func (s *archiveSource) Push(version string) error {
+	return fmt.Errorf("%w: %s is an archive", ErrPushNotSupported, s.url)
+}
+
+// dirSource serves a local directory that is not a git repository. Its
+// revision is the checksum of its content.
+type dirSource struct {
+	dir string
+}
+
+func (s *dirSource) Versions() ([]string, error) {
+	return []string{LOCAL_VERSION}, nil
+}
+
+func (s *dirSource) Resolve(version string) (string, error) {
+	if version != "" && version != LOCAL_VERSION {
+		return "", fmt.Errorf("%s is not a git repository, only version %q exists", s.dir, LOCAL_VERSION)
+	}
+	files, err := s.files()
+	if err != nil {
+		return "", err
+	}
+	return CHECKSUM_PREFIX + filesDigest(files), nil
+}
+
+func (s *dirSource) Export(revision string) (map[string][]byte, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	if sum := CHECKSUM_PREFIX + filesDigest(files); sum != revision {
+		return nil, fmt.Errorf("%s changed since it was resolved", s.dir)
+	}
+	return files, nil
+}
+
+func (s *dirSource) files() (map[string][]byte, error) {
+	return util.ReadDirFiles(s.dir, []string{".git"})
+}
+
+func (s *dirSource) Push(version string) error {
+	return fmt.Errorf("%w: %s is a local directory", ErrPushNotSupported, s.dir)
+}
+
+func filesDigest(files map[string][]byte) string {
+	hashes := make(map[string]string, len(files))
+	for p, data := range files {
+		hashes[p] = hashBytes(data)
+	}
+	return treeDigest(hashes)
+}
@@ -0,0 +1,128 @@
+package root_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySource serve versões a partir de um mapa
+type memorySource map[string]map[string][]byte
+
+func (m memorySource) Versions() ([]string, error) {
+	var versions []string
+	for v := range m {
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+func (m memorySource) Resolve(version string) (string, error) {
+	if _, ok := m[version]; !ok {
+		return "", fmt.Errorf("unknown version %s", version)
+	}
+	return "rev-" + version, nil
+}
+func (m memorySource) Export(revision string) (map[string][]byte, error) {
+	return m[revision[len("rev-"):]], nil
+}
+func (m memorySource) Push(version string) error {
+	return root.ErrPushNotSupported
+}
+
+func TestRegisterSource(t *testing.T) {
+	useTempStore(t)
+	r := &root.RootConfig{}
+	r.RegisterSource("mem", func(url string) (root.Source, error) {
+		return memorySource{"v1.0.0": {"ui/button.go": []byte("button")}}, nil
+	})
+
+	versions, err := r.Versions("mem://ui")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0"}, versions)
+
+	dst := t.TempDir()
+	require.NoError(t, r.CopyRootFiles("mem://ui", "v1.0.0", dst, nil))
+	data, err := os.ReadFile(filepath.Join(dst, "ui", "button.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "button", string(data))
+
+	_, err = r.Resolve("mem://ui", "v2.0.0")
+	assert.Error(t, err)
+}
+
+func TestSource_LocalDirectory(t *testing.T) {
+	useTempStore(t)
+	r := &root.RootConfig{}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("a"), 0644))
+
+	versions, err := r.Versions(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{root.LOCAL_VERSION}, versions)
+
+	files, err := r.ReadPackageFiles(dir, root.LOCAL_VERSION)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a.go": []byte("a")}, files)
+
+	_, err = r.Resolve(dir, "v1.0.0")
+	assert.Error(t, err)
+	src, err := r.Source(dir)
+	require.NoError(t, err)
+	assert.ErrorIs(t, src.Push("v1.0.0"), root.ErrPushNotSupported)
+}
+
+func TestRecord_ReadsBackAfterChange(t *testing.T) {
+	useTempStore(t)
+	r := &root.RootConfig{}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("a"), 0644))
+
+	files, err := r.ReadPackageFiles(dir, root.LOCAL_VERSION)
+	require.NoError(t, err)
+	revision, err := r.Record(dir, files)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(revision, root.CHECKSUM_PREFIX))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("b"), 0644))
+	recorded, err := r.ReadPackageFiles(dir, revision)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a.go": []byte("a")}, recorded)
+}
+
+func TestSource_LocalGitRepository(t *testing.T) {
+	r := &root.RootConfig{}
+	initCachedPackage(t, r, "https://example.com/org/upstream.git", "v1.0.0")
+	upstream := r.BuildRootPackagePath("https://example.com/org/upstream.git")
+
+	dir := t.TempDir()
+	for _, url := range []string{filepath.Join(dir, "path.git"), "file://" + filepath.Join(dir, "file.git")} {
+		_, err := git.PlainClone(strings.TrimPrefix(url, "file://"), true, &git.CloneOptions{URL: upstream})
+		require.NoError(t, err)
+
+		versions, err := r.Versions(url)
+		require.NoError(t, err, url)
+		assert.Equal(t, []string{"v1.0.0"}, versions, url)
+	}
+}
+
+func TestSource_GitResolvesTags(t *testing.T) {
+	r := &root.RootConfig{}
+	url := "https://example.com/org/fetch.git"
+	repo := initCachedPackage(t, r, url, "v1.0.0")
+
+	revision, err := r.Resolve(url, "v1.0.0")
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash().String(), revision)
+
+	_, err = r.Resolve(url, "")
+	assert.ErrorContains(t, err, "tag is required")
+}
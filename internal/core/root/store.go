@@ -0,0 +1,309 @@
+package root
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+var DEFAULT_ROOT_STORE_PATH = filepath.Join(DEFAULT_ROOT_PATH, "store")
+
+// SnapshotKey identifies a resolved package tree: the files of a commit of
+// Url, without the Ignore names.
+type SnapshotKey struct {
+	Url    string   `json:"url"`
+	Commit string   `json:"commit"`
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+func (k SnapshotKey) id() string {
+	data, _ := json.Marshal(k)
+	return hashBytes(data)
+}
+
+// Snapshot is a package tree kept in the store. Files maps each path to the
+// sha256 of its content and Digest hashes the whole tree, so equal trees are
+// stored once whatever commit they come from.
+type Snapshot struct {
+	Key    SnapshotKey       `json:"key"`
+	Digest string            `json:"digest"`
+	Files  map[string]string `json:"files"`
+
+	Dir string `json:"-"`
+}
+
+// Store is a content-addressable store of package snapshots. File contents
+// live once in objects, and every snapshot directory hard links them. All of
+// it is read-only and must never be edited in place.
+//
+//	objects/<ab>/<sha256>    file contents
+//	snapshots/<digest>/      snapshot trees
+//	manifests/<digest>.json  snapshot file lists
+//	index/<key id>           digest of a snapshot key
+type Store struct {
+	Path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{Path: path}
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func treeDigest(files map[string]string) string {
+	paths := util.MapKeys(files)
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, p := range paths {
+		fmt.Fprintf(&b, "%s\x00%s\n", p, files[p])
+	}
+	return hashBytes([]byte(b.String()))
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.Path, "objects", hash[:2], hash)
+}
+
+func (s *Store) snapshotDir(digest string) string {
+	return filepath.Join(s.Path, "snapshots", digest)
+}
+
+func (s *Store) manifestPath(digest string) string {
+	return filepath.Join(s.Path, "manifests", digest+".json")
+}
+
+func (s *Store) indexPath(key SnapshotKey) string {
+	return filepath.Join(s.Path, "index", key.id())
+}
+
+// Get returns the snapshot stored for key, or nil when there is none.
+func (s *Store) Get(key SnapshotKey) (*Snapshot, error) {
+	digest, err := os.ReadFile(s.indexPath(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.load(strings.TrimSpace(string(digest)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
+func (s *Store) load(digest string) (*Snapshot, error) {
+	data, err := os.ReadFile(s.manifestPath(digest))
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", digest, err)
+	}
+	snapshot.Dir = s.snapshotDir(digest)
+	if _, err := os.Stat(snapshot.Dir); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Put stores files as the snapshot of key, without its ignored names, and
+// returns it.
+func (s *Store) Put(key SnapshotKey, files map[string][]byte) (*Snapshot, error) {
+	files = filterFiles(files, key.Ignore)
+	hashes := make(map[string]string, len(files))
+	for p, data := range files {
+		hash := hashBytes(data)
+		if err := s.writeObject(hash, data); err != nil {
+			return nil, err
+		}
+		hashes[p] = hash
+	}
+
+	snapshot := &Snapshot{Key: key, Digest: treeDigest(hashes), Files: hashes}
+	snapshot.Dir = s.snapshotDir(snapshot.Digest)
+	if _, err := os.Stat(snapshot.Dir); os.IsNotExist(err) {
+		err := util.ReplaceDir(snapshot.Dir, func(tmp string) error {
+			for p, hash := range hashes {
+				target := filepath.Join(tmp, filepath.FromSlash(p))
+				if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+					return err
+				}
+				if err := util.LinkFile(s.objectPath(hash), target); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := util.WriteFileAtomic(s.manifestPath(snapshot.Digest), manifest, 0444); err != nil {
+		return nil, err
+	}
+	if err := util.WriteFileAtomic(s.indexPath(key), []byte(snapshot.Digest+"\n"), 0644); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// PutObject stores a single blob, such as a downloaded archive, and returns
+// its sha256.
+func (s *Store) PutObject(data []byte) (string, error) {
+	hash := hashBytes(data)
+	return hash, s.writeObject(hash, data)
+}
+
+// ReadObject returns the blob stored under hash, checking its content.
+func (s *Store) ReadObject(hash string) ([]byte, error) {
+	data, err := os.ReadFile(s.objectPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	if hashBytes(data) != hash {
+		return nil, fmt.Errorf("object %s does not match its hash", hash)
+	}
+	return data, nil
+}
+
+// RemoveObject deletes the blob stored under hash, if any.
+func (s *Store) RemoveObject(hash string) error {
+	if err := os.Remove(s.objectPath(hash)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ReadFiles returns the content of every file of snapshot.
+func (s *Store) ReadFiles(snapshot *Snapshot) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(snapshot.Files))
+	for p, hash := range snapshot.Files {
+		data, err := s.ReadObject(hash)
+		if err != nil {
+			return nil, err
+		}
+		files[p] = data
+	}
+	return files, nil
+}
+
+func (s *Store) writeObject(hash string, data []byte) error {
+	target := s.objectPath(hash)
+	if _, err := os.Stat(target); err == nil {
+		return nil
+	}
+	return util.WriteFileAtomic(target, data, 0444)
+}
+
+// filterFiles drops the files with an ignored name in their path.
+func filterFiles(files map[string][]byte, ignore []string) map[string][]byte {
+	filtered := map[string][]byte{}
+	for p, data := range files {
+		if ignoredPath(p, ignore) {
+			continue
+		}
+		filtered[p] = data
+	}
+	return filtered
+}
+
+func ignoredPath(p string, ignore []string) bool {
+	for _, part := range strings.Split(p, "/") {
+		for _, name := range ignore {
+			if part == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Verify checks the snapshot directory and the objects it links against the
+// manifest and returns the paths that are modified, missing or extraneous.
+func (s *Store) Verify(snapshot *Snapshot) ([]string, error) {
+	files, err := util.ReadDirFiles(snapshot.Dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	var corrupt []string
+	for p, hash := range snapshot.Files {
+		data, ok := files[p]
+		if !ok || hashBytes(data) != hash {
+			corrupt = append(corrupt, p)
+			continue
+		}
+		if object, err := os.ReadFile(s.objectPath(hash)); err == nil && hashBytes(object) != hash {
+			corrupt = append(corrupt, p)
+		}
+	}
+	for p := range files {
+		if _, ok := snapshot.Files[p]; !ok {
+			corrupt = append(corrupt, p)
+		}
+	}
+	sort.Strings(corrupt)
+	return corrupt, nil
+}
+
+// Remove deletes a snapshot and the objects of its corrupt paths, so the
+// next Put writes them again.
+func (s *Store) Remove(snapshot *Snapshot, corrupt []string) error {
+	for _, p := range corrupt {
+		if hash, ok := snapshot.Files[p]; ok {
+			if err := s.RemoveObject(hash); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.RemoveAll(snapshot.Dir); err != nil {
+		return err
+	}
+	if err := os.Remove(s.manifestPath(snapshot.Digest)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns every snapshot of the store.
+func (s *Store) List() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Path, "manifests"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		digest, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		snapshot, err := s.load(digest)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,7 @@
+package mirror
+
+import "errors"
+
+var (
+	ErrMissingRoot = errors.New("mirror root does not exist")
+)
@@ -0,0 +1,60 @@
+package mirror
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// Ignore tells which paths of a mirror group, relative to its roots, are
+// left out of mirroring: the global and group patterns, in gitignore syntax,
+// and the .gitignore files of the roots when the group honors them.
+type Ignore struct {
+	matchers []gitignore.Matcher
+}
+
+func NewIgnore(group root.MirrorGroup) *Ignore {
+	var patterns []gitignore.Pattern
+	for _, p := range group.Ignore {
+		if p = strings.TrimSpace(p); p != "" && !strings.HasPrefix(p, "#") {
+			patterns = append(patterns, gitignore.ParsePattern(p, nil))
+		}
+	}
+	ignore := &Ignore{}
+	if !group.GitIgnore {
+		ignore.matchers = append(ignore.matchers, gitignore.NewMatcher(patterns))
+		return ignore
+	}
+	// every root may hold its own .gitignore files, a path ignored by any
+	// of them is ignored in the whole group
+	for _, path := range group.Paths {
+		rootPatterns, err := gitignore.ReadPatterns(osfs.New(path), nil)
+		if err != nil {
+			log.Printf("Error reading .gitignore files of %s: %v", path, err)
+		}
+		ignore.matchers = append(ignore.matchers, gitignore.NewMatcher(append(patterns, rootPatterns...)))
+	}
+	return ignore
+}
+
+// Match reports whether rel is ignored. A nil Ignore ignores nothing.
+func (i *Ignore) Match(rel string, isDir bool) bool {
+	if i == nil || rel == "." || rel == "" {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, m := range i.matchers {
+		if m.Match(parts, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+func isGitIgnoreFile(path string) bool {
+	return filepath.Base(path) == ".gitignore"
+}
@@ -0,0 +1,49 @@
+package mirror
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnore_Match(t *testing.T) {
+	ignore := NewIgnore(root.MirrorGroup{Ignore: []string{".git/", "node_modules", "*.swp", "/build", "# comment"}})
+
+	assert.True(t, ignore.Match(".git", true))
+	assert.True(t, ignore.Match(filepath.Join(".git", "HEAD"), false))
+	assert.True(t, ignore.Match(filepath.Join("web", "node_modules", "react", "index.js"), false))
+	assert.True(t, ignore.Match(filepath.Join("src", ".main.go.swp"), false))
+	assert.True(t, ignore.Match("build", true))
+	assert.False(t, ignore.Match(filepath.Join("src", "build"), true))
+	assert.False(t, ignore.Match(filepath.Join("src", "main.go"), false))
+
+	var none *Ignore
+	assert.False(t, none.Match("anything", false))
+}
+
+func TestReconcile_SkipsIgnored(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	group := root.MirrorGroup{Paths: []string{a, b}, GitIgnore: true}.WithIgnore([]string{"*.swp"})
+	makeRoots(t, group.Paths...)
+	state, err := LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	writeAt(t, filepath.Join(a, ".gitignore"), "dist/\n", now)
+	writeAt(t, filepath.Join(a, "main.go"), "package main", now)
+	writeAt(t, filepath.Join(a, ".main.go.swp"), "swap", now)
+	writeAt(t, filepath.Join(a, "dist", "app"), "binary", now)
+
+	require.NoError(t, Reconcile(group, state))
+
+	assert.FileExists(t, filepath.Join(b, "main.go"))
+	assert.FileExists(t, filepath.Join(b, ".gitignore"))
+	assert.NoFileExists(t, filepath.Join(b, ".main.go.swp"))
+	assert.NoDirExists(t, filepath.Join(b, "dist"))
+	assert.NotContains(t, state.Files(group.Paths), "dist/app")
+}
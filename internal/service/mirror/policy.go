@@ -0,0 +1,56 @@
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+)
+
+// resolve picks, among copies of a file changed since the last sync, the one
+// the group keeps. Losers are the other copies with a different content,
+// saved as conflict copies by the keep-both policy.
+func resolve(group root.MirrorGroup, changed []copyState) (winner copyState, losers []copyState) {
+	winner = changed[0]
+	for _, c := range changed[1:] {
+		if c.ModTime.After(winner.ModTime) {
+			winner = c
+		}
+	}
+	if group.ConflictPolicy() == root.SourceOfTruth {
+		for _, c := range changed {
+			if c.root == group.Source {
+				winner = c
+			}
+		}
+	}
+	seen := map[string]bool{winner.Hash: true}
+	for _, c := range changed {
+		if !seen[c.Hash] {
+			seen[c.Hash] = true
+			losers = append(losers, c)
+		}
+	}
+	if len(losers) > 0 {
+		names := []string{}
+		for _, c := range losers {
+			names = append(names, c.root)
+		}
+		log.Printf("Conflict: %s wins over %s (%s)", winner.root, strings.Join(names, ", "), group.ConflictPolicy())
+	}
+	return winner, losers
+}
+
+// conflictName names the conflict copy of rel coming from root, e.g.
+// notes.conflict-laptop-1a2b3c4d-20240102-150405.md. The hash of the root
+// path tells apart roots sharing a base name.
+func conflictName(rel, root string, at time.Time) string {
+	ext := filepath.Ext(rel)
+	sum := sha256.Sum256([]byte(root))
+	return fmt.Sprintf("%s.conflict-%s-%s-%s%s", strings.TrimSuffix(rel, ext), filepath.Base(root), hex.EncodeToString(sum[:4]), at.Format("20060102-150405"), ext)
+}
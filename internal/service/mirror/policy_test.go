@@ -0,0 +1,17 @@
+package mirror
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflictName_SameBaseName(t *testing.T) {
+	at := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	a := conflictName("notes.md", "/home/me/work/notes", at)
+	b := conflictName("notes.md", "/mnt/backup/notes", at)
+	assert.Regexp(t, `^notes\.conflict-notes-[0-9a-f]{8}-20240102-150405\.md$`, a)
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,153 @@
+package mirror
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
+)
+
+// copyState is a file as found in one root of a group.
+type copyState struct {
+	root string
+	FileState
+}
+
+// Reconcile brings the roots of a group in sync with each other, for changes
+// made while the service was not watching them:
+//   - a file missing from some roots and unchanged in the others since the
+//     last sync was deleted, and is deleted everywhere
+//   - otherwise the copies changed since the last sync are resolved with the
+//     group policy and the winner is copied where it is missing or differs
+//
+// The group state is then recorded. Every root must exist: a missing one,
+// such as an unmounted drive, would look like all its files were deleted.
+func Reconcile(group root.MirrorGroup, state *State) error {
+	paths := group.Paths
+	ignore := NewIgnore(group)
+	if missing := missingRoot(paths); missing != "" {
+		return fmt.Errorf("%w: %s", ErrMissingRoot, missing)
+	}
+	found := map[string][]copyState{}
+	for _, root := range paths {
+		files, err := scanRoot(root, ignore.Match)
+		if err != nil {
+			return err
+		}
+		for rel, file := range files {
+			found[rel] = append(found[rel], copyState{root: root, FileState: file})
+		}
+	}
+
+	previous := state.Files(paths)
+	synced := map[string]FileState{}
+	for rel, copies := range found {
+		last, known := previous[rel]
+		if known && len(copies) < len(paths) && unchangedSince(copies, last) {
+			for _, c := range copies {
+				log.Printf("Removing %s, deleted from another mirror", filepath.Join(c.root, rel))
+				if err := os.Remove(filepath.Join(c.root, filepath.FromSlash(rel))); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		files, err := syncFile(group, rel, copies, last, known)
+		if err != nil {
+			return err
+		}
+		for name, file := range files {
+			synced[name] = file
+		}
+	}
+	return state.SetFiles(paths, synced)
+}
+
+func unchangedSince(copies []copyState, last FileState) bool {
+	for _, c := range copies {
+		if c.Hash != last.Hash {
+			return false
+		}
+	}
+	return true
+}
+
+func hasCopy(copies []copyState, root, hash string) bool {
+	for _, c := range copies {
+		if c.root == root {
+			return c.Hash == hash
+		}
+	}
+	return false
+}
+
+// scanRoot returns the state of every regular file under root, keyed by its
+// slash separated relative path, skipping the ignored paths.
+func scanRoot(root string, ignored func(rel string, isDir bool) bool) (map[string]FileState, error) {
+	files := map[string]FileState{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || isTempFile(path) {
+			return nil
+		}
+		file, err := fileState(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = *file
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// TEMP_PREFIX starts the names of the temporary files destinations are
+// written through, which the watcher skips.
+const TEMP_PREFIX = ".zetten-mirror-"
+
+// copyPreservingTime copies src to dst keeping its modification time, so
+// both compare as the same version later on. The copy is written to a
+// temporary file renamed over dst, so dst is never seen half written.
+func copyPreservingTime(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(dst), fmt.Sprintf("%s%s-%d", TEMP_PREFIX, filepath.Base(dst), os.Getpid()))
+	if err := util.CopyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+func isTempFile(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), TEMP_PREFIX)
+}
@@ -0,0 +1,175 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAt(t *testing.T, path, content string, at time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, at, at))
+}
+
+func makeRoots(t *testing.T, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		require.NoError(t, os.MkdirAll(path, 0755))
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestReconcile_CopiesNewest(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	paths := []string{a, b}
+	makeRoots(t, paths...)
+	state, err := LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	writeAt(t, filepath.Join(a, "old.txt"), "old a", now.Add(-time.Hour))
+	writeAt(t, filepath.Join(b, "old.txt"), "new b", now)
+	writeAt(t, filepath.Join(a, "sub", "only-a.txt"), "only a", now)
+
+	require.NoError(t, Reconcile(root.MirrorGroup{Paths: paths}, state))
+
+	assert.Equal(t, "new b", readFile(t, filepath.Join(a, "old.txt")))
+	assert.Equal(t, "only a", readFile(t, filepath.Join(b, "sub", "only-a.txt")))
+
+	// the state survives a restart
+	loaded, err := LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+	assert.Len(t, loaded.Files(paths), 2)
+}
+
+func TestReconcile_PropagatesOfflineDeletions(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	paths := []string{a, b}
+	makeRoots(t, paths...)
+	state, err := LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	writeAt(t, filepath.Join(a, "deleted.txt"), "x", now)
+	writeAt(t, filepath.Join(a, "edited.txt"), "v1", now)
+	require.NoError(t, Reconcile(root.MirrorGroup{Paths: paths}, state))
+
+	// while stopped: deleted.txt is removed from b, edited.txt removed from a
+	// but edited in b
+	require.NoError(t, os.Remove(filepath.Join(b, "deleted.txt")))
+	require.NoError(t, os.Remove(filepath.Join(a, "edited.txt")))
+	writeAt(t, filepath.Join(b, "edited.txt"), "v2", now.Add(time.Minute))
+
+	require.NoError(t, Reconcile(root.MirrorGroup{Paths: paths}, state))
+
+	assert.NoFileExists(t, filepath.Join(a, "deleted.txt"))
+	assert.Equal(t, "v2", readFile(t, filepath.Join(a, "edited.txt")))
+	assert.NotContains(t, state.Files(paths), "deleted.txt")
+}
+
+func conflictingGroup(t *testing.T, group root.MirrorGroup) (root.MirrorGroup, *State) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	group.Paths = []string{a, b}
+	makeRoots(t, group.Paths...)
+	if group.Source != "" {
+		group.Source = a
+	}
+	state, err := LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	writeAt(t, filepath.Join(a, "notes.md"), "v1", now.Add(-time.Hour))
+	require.NoError(t, Reconcile(group, state))
+
+	// both copies change, b last
+	writeAt(t, filepath.Join(a, "notes.md"), "from a", now.Add(-time.Minute))
+	writeAt(t, filepath.Join(b, "notes.md"), "from b", now)
+	return group, state
+}
+
+func TestReconcile_NewestWins(t *testing.T) {
+	group, state := conflictingGroup(t, root.MirrorGroup{Policy: root.NewestWins})
+	require.NoError(t, Reconcile(group, state))
+
+	for _, path := range group.Paths {
+		assert.Equal(t, "from b", readFile(t, filepath.Join(path, "notes.md")))
+	}
+}
+
+func TestReconcile_SourceOfTruth(t *testing.T) {
+	group, state := conflictingGroup(t, root.MirrorGroup{Policy: root.SourceOfTruth, Source: "a"})
+	require.NoError(t, Reconcile(group, state))
+
+	for _, path := range group.Paths {
+		assert.Equal(t, "from a", readFile(t, filepath.Join(path, "notes.md")))
+	}
+}
+
+func TestReconcile_KeepBoth(t *testing.T) {
+	group, state := conflictingGroup(t, root.MirrorGroup{Policy: root.KeepBoth})
+	require.NoError(t, Reconcile(group, state))
+
+	for _, path := range group.Paths {
+		assert.Equal(t, "from b", readFile(t, filepath.Join(path, "notes.md")))
+		conflicts, err := filepath.Glob(filepath.Join(path, "notes.conflict-a-*.md"))
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, "from a", readFile(t, conflicts[0]))
+	}
+}
+
+func TestPropagate_SuppressesEchoes(t *testing.T) {
+	dir := t.TempDir()
+	group := root.MirrorGroup{Paths: []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}}
+	makeRoots(t, group.Paths...)
+	var err error
+	state, err = LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+	require.NoError(t, Reconcile(group, state))
+
+	writeAt(t, filepath.Join(dir, "a", "file.txt"), "hello", time.Now())
+	propagate(group, "file.txt")
+	assert.Equal(t, "hello", readFile(t, filepath.Join(dir, "b", "file.txt")))
+	synced, _ := state.File(group.Paths, "file.txt")
+
+	// the write event of the copy in b finds it in sync and does nothing
+	propagate(group, "file.txt")
+	after, _ := state.File(group.Paths, "file.txt")
+	assert.Equal(t, synced, after)
+}
+
+func TestReconcile_RefusesMissingRoot(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	paths := []string{a, b}
+	makeRoots(t, paths...)
+	state, err := LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+	writeAt(t, filepath.Join(a, "important.txt"), "keep me", time.Now())
+	require.NoError(t, Reconcile(root.MirrorGroup{Paths: paths}, state))
+
+	// b is unmounted: its files must not look deleted
+	require.NoError(t, os.RemoveAll(b))
+	err = Reconcile(root.MirrorGroup{Paths: paths}, state)
+	assert.ErrorIs(t, err, ErrMissingRoot)
+
+	assert.Equal(t, "keep me", readFile(t, filepath.Join(a, "important.txt")))
+	assert.NoDirExists(t, b)
+	assert.Contains(t, state.Files(paths), "important.txt")
+}
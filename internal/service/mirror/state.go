@@ -0,0 +1,205 @@
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/core-stack/zetten-cli/internal/util"
+	"github.com/goccy/go-yaml"
+)
+
+var DEFAULT_STATE_PATH = filepath.Join(root.DEFAULT_ROOT_PATH, "mirror-state.yml")
+
+// FileState is what a mirrored file looked like when it was last in sync
+// across its group.
+type FileState struct {
+	Hash    string    `yaml:"hash"`
+	ModTime time.Time `yaml:"modTime"`
+}
+
+type GroupState struct {
+	// Files are keyed by their slash separated path relative to the roots
+	Files map[string]FileState `yaml:"files"`
+}
+
+// State remembers the files of every mirror group across runs of the
+// service, so files deleted while it was stopped can be told apart from
+// files that are new.
+type State struct {
+	Groups map[string]*GroupState `yaml:"groups"`
+
+	path string
+	mu   sync.Mutex
+	// batching defers saving to the end of a batch
+	batching bool
+	dirty    bool
+}
+
+func LoadState(path string) (*State, error) {
+	state := &State{Groups: map[string]*GroupState{}, path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Groups == nil {
+		state.Groups = map[string]*GroupState{}
+	}
+	return state, nil
+}
+
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save()
+}
+
+func (s *State) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if s.batching {
+		s.dirty = true
+		return nil
+	}
+	return util.SaveYAMLIndented(s.path, s)
+}
+
+// Batch runs fn saving the state once at the end rather than on every
+// change.
+func (s *State) Batch(fn func()) error {
+	s.mu.Lock()
+	s.batching = true
+	s.mu.Unlock()
+
+	fn()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batching = false
+	if !s.dirty {
+		return nil
+	}
+	s.dirty = false
+	return s.save()
+}
+
+// groupKey identifies a group by its paths.
+func groupKey(paths []string) string {
+	return strings.Join(paths, string(os.PathListSeparator))
+}
+
+// Files returns a copy of the recorded files of the group.
+func (s *State) Files(paths []string) map[string]FileState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := map[string]FileState{}
+	if group, ok := s.Groups[groupKey(paths)]; ok {
+		for rel, file := range group.Files {
+			files[rel] = file
+		}
+	}
+	return files
+}
+
+// File returns the recorded state of a single file of the group.
+func (s *State) File(paths []string, rel string) (FileState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if group, ok := s.Groups[groupKey(paths)]; ok {
+		file, ok := group.Files[filepath.ToSlash(rel)]
+		return file, ok
+	}
+	return FileState{}, false
+}
+
+// RemoveDir forgets the recorded files under the directory rel of the group
+// and returns their paths.
+func (s *State) RemoveDir(paths []string, rel string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group, ok := s.Groups[groupKey(paths)]
+	if !ok {
+		return nil, nil
+	}
+	prefix := filepath.ToSlash(rel) + "/"
+	var removed []string
+	for name := range group.Files {
+		if strings.HasPrefix(name, prefix) {
+			removed = append(removed, name)
+			delete(group.Files, name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, s.save()
+}
+
+// SetFiles replaces the recorded files of the group and saves the state.
+func (s *State) SetFiles(paths []string, files map[string]FileState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Groups[groupKey(paths)] = &GroupState{Files: files}
+	return s.save()
+}
+
+// Record updates a single file of the group, removing it when file is nil,
+// and saves the state.
+func (s *State) Record(paths []string, rel string, file *FileState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := groupKey(paths)
+	group, ok := s.Groups[key]
+	if !ok {
+		group = &GroupState{Files: map[string]FileState{}}
+		s.Groups[key] = group
+	}
+	if group.Files == nil {
+		group.Files = map[string]FileState{}
+	}
+	rel = filepath.ToSlash(rel)
+	if file == nil {
+		delete(group.Files, rel)
+	} else {
+		group.Files[rel] = *file
+	}
+	return s.save()
+}
+
+func fileState(path string) (*FileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := fileHash(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileState{Hash: hash, ModTime: info.ModTime()}, nil
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
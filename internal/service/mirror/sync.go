@@ -0,0 +1,89 @@
+package mirror
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+)
+
+// collectCopies returns the copies of rel found in the roots of the group.
+func collectCopies(paths []string, rel string) []copyState {
+	var copies []copyState
+	for _, root := range paths {
+		file, err := fileState(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		copies = append(copies, copyState{root: root, FileState: *file})
+	}
+	return copies
+}
+
+// syncFile makes every root of the group hold the copy of rel the group
+// keeps, among the copies changed since the last sync. It returns the synced
+// state of rel and of the conflict copies written.
+func syncFile(group root.MirrorGroup, rel string, copies []copyState, last FileState, known bool) (map[string]FileState, error) {
+	var changed []copyState
+	for _, c := range copies {
+		if !known || c.Hash != last.Hash {
+			changed = append(changed, c)
+		}
+	}
+	if len(changed) == 0 {
+		changed = copies
+	}
+	winner, losers := resolve(group, changed)
+	synced := map[string]FileState{rel: winner.FileState}
+
+	// conflict copies are saved before the winner replaces the losers
+	if group.ConflictPolicy() == root.KeepBoth {
+		for _, loser := range losers {
+			name := conflictName(rel, loser.root, loser.ModTime)
+			src := filepath.Join(loser.root, filepath.FromSlash(rel))
+			for _, root := range group.Paths {
+				dst := filepath.Join(root, filepath.FromSlash(name))
+				log.Printf("Keeping conflicting %s as %s", src, dst)
+				if err := copyPreservingTime(src, dst); err != nil {
+					return nil, err
+				}
+			}
+			synced[name] = loser.FileState
+		}
+	}
+
+	src := filepath.Join(winner.root, filepath.FromSlash(rel))
+	for _, root := range group.Paths {
+		if hasCopy(copies, root, winner.Hash) {
+			continue
+		}
+		dst := filepath.Join(root, filepath.FromSlash(rel))
+		log.Printf("Copying %s → %s", src, dst)
+		if err := copyPreservingTime(src, dst); err != nil {
+			return nil, err
+		}
+	}
+	return synced, nil
+}
+
+// removeFile deletes rel from the roots where it is unchanged since the last
+// sync. Copies changed meanwhile win over the deletion and are synced back.
+func removeFile(group root.MirrorGroup, rel string, last FileState) (map[string]FileState, error) {
+	var changed []copyState
+	for _, c := range collectCopies(group.Paths, rel) {
+		if c.Hash != last.Hash {
+			changed = append(changed, c)
+			continue
+		}
+		path := filepath.Join(c.root, filepath.FromSlash(rel))
+		log.Printf("Removing %s", path)
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return syncFile(group, rel, changed, last, true)
+}
@@ -1,12 +1,13 @@
 package mirror
 
 import (
-	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/core-stack/zetten-cli/internal/core/root"
 	"github.com/fsnotify/fsnotify"
@@ -20,6 +21,7 @@ var (
 	wg      sync.WaitGroup
 	stopCh  = make(chan struct{})
 	watched = make(map[string]struct{})
+	state   *State
 )
 
 func StartMirrorService(configPath string) error {
@@ -27,7 +29,21 @@ func StartMirrorService(configPath string) error {
 	if err != nil {
 		return err
 	}
+	state, err = LoadState(DEFAULT_STATE_PATH)
+	if err != nil {
+		return err
+	}
 	for _, group := range cfg.Mirror {
+		group = group.WithIgnore(cfg.MirrorIgnore)
+		// changes made while the service was stopped are synced first
+		if err := group.Validate(); err != nil {
+			log.Printf("Skipping mirror group %v: %v", group.Paths, err)
+			continue
+		}
+		if err := Reconcile(group, state); err != nil {
+			log.Printf("Skipping mirror group %v: %v", group.Paths, err)
+			continue
+		}
 		wg.Add(1)
 		go watchGroup(group)
 	}
@@ -41,8 +57,9 @@ func StopMirrorService() {
 	wg.Wait()
 }
 
-func watchGroup(paths []string) {
+func watchGroup(group root.MirrorGroup) {
 	defer wg.Done()
+	paths := group.Paths
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -50,11 +67,19 @@ func watchGroup(paths []string) {
 	}
 	defer watcher.Close()
 
+	ignore := NewIgnore(group)
 	for _, root := range paths {
-		addRecursive(watcher, root)
+		addRecursive(watcher, root, root, ignore)
 	}
 
-	log.Printf("Monitoring paths: %v", paths)
+	log.Printf("Monitoring paths: %v (%s)", paths, group.ConflictPolicy())
+
+	// changes wait for their path to stay quiet, editors emit bursts of
+	// events for a single save
+	quiet := group.QuietPeriod()
+	pending := map[string]time.Time{}
+	ticker := time.NewTicker(max(quiet/4, 10*time.Millisecond))
+	defer ticker.Stop()
 
 	for {
 		select {
@@ -64,32 +89,32 @@ func watchGroup(paths []string) {
 			if !ok {
 				return
 			}
+			if isTempFile(event.Name) || event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
 			source := findSourceRoot(paths, event.Name)
 			if source == "" {
 				continue
 			}
 			relPath, _ := filepath.Rel(source, event.Name)
-
-			for _, destRoot := range paths {
-				if destRoot == source {
-					continue
-				}
-				destPath := filepath.Join(destRoot, relPath)
-
-				switch {
-				case event.Op&fsnotify.Create == fsnotify.Create:
-					fi, err := os.Stat(event.Name)
-					if err == nil && fi.IsDir() {
-						addRecursive(watcher, event.Name)
-					} else {
-						copyFile(event.Name, destPath)
-					}
-				case event.Op&fsnotify.Write == fsnotify.Write:
-					copyFile(event.Name, destPath)
-				case event.Op&fsnotify.Remove == fsnotify.Remove:
-					os.RemoveAll(destPath)
-				case event.Op&fsnotify.Rename == fsnotify.Rename:
-					os.RemoveAll(destPath)
+			fi, err := os.Stat(event.Name)
+			isDir := err == nil && fi.IsDir()
+			if ignore.Match(relPath, isDir) {
+				continue
+			}
+			if group.GitIgnore && isGitIgnoreFile(event.Name) {
+				ignore = NewIgnore(group)
+			}
+			// new directories are watched right away so the events of their
+			// content are not missed
+			if event.Op&fsnotify.Create != 0 && isDir {
+				addRecursive(watcher, source, event.Name, ignore)
+			}
+			pending[relPath] = time.Now().Add(quiet)
+		case <-ticker.C:
+			if due := dueEvents(pending, time.Now()); len(due) > 0 {
+				if err := state.Batch(func() { propagateBatch(group, ignore, due) }); err != nil {
+					log.Printf("Error saving mirror state: %v", err)
 				}
 			}
 		case err, ok := <-watcher.Errors:
@@ -101,6 +126,163 @@ func watchGroup(paths []string) {
 	}
 }
 
+// dueEvents removes from pending the paths quiet since their deadline and
+// returns them sorted, parents before their content.
+func dueEvents(pending map[string]time.Time, now time.Time) []string {
+	var due []string
+	for rel, deadline := range pending {
+		if !now.Before(deadline) {
+			due = append(due, rel)
+			delete(pending, rel)
+		}
+	}
+	sort.Strings(due)
+	return due
+}
+
+// propagateBatch propagates the paths of a batch as they are now on disk,
+// whatever the events that queued them. Paths inside a directory of the
+// batch are covered by the directory.
+func propagateBatch(group root.MirrorGroup, ignore *Ignore, due []string) {
+	// a root that went away, e.g. an unmounted drive, is not a deletion
+	if missing := missingRoot(group.Paths); missing != "" {
+		log.Printf("Not syncing %v: %v: %s", group.Paths, ErrMissingRoot, missing)
+		return
+	}
+	var dirs []string
+	for _, relPath := range due {
+		if insideAny(relPath, dirs) {
+			continue
+		}
+		info := findCurrent(group.Paths, relPath)
+		if info != nil && ignore.Match(relPath, info.IsDir()) {
+			continue
+		}
+		switch {
+		case info == nil:
+			propagateRemoval(group, relPath)
+		case info.IsDir():
+			dirs = append(dirs, relPath)
+			propagateDir(group, ignore, relPath)
+		default:
+			propagate(group, relPath)
+		}
+	}
+}
+
+// missingRoot returns the first root of paths that is not a directory.
+func missingRoot(paths []string) string {
+	for _, root := range paths {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return root
+		}
+	}
+	return ""
+}
+
+// findCurrent returns what relPath is in the first root holding it.
+func findCurrent(paths []string, relPath string) os.FileInfo {
+	for _, root := range paths {
+		if info, err := os.Stat(filepath.Join(root, relPath)); err == nil {
+			return info
+		}
+	}
+	return nil
+}
+
+func insideAny(relPath string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(relPath, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// propagate syncs a file changed in one root to the others. A write made by
+// the service itself leaves the file with the hash recorded in the state, so
+// it is recognised and not propagated back.
+func propagate(group root.MirrorGroup, relPath string) {
+	copies := collectCopies(group.Paths, relPath)
+	if len(copies) == 0 {
+		return
+	}
+	last, known := state.File(group.Paths, relPath)
+	if known && unchangedSince(copies, last) {
+		// missing from some roots and unchanged in the others, it was
+		// deleted
+		if len(copies) < len(group.Paths) {
+			propagateRemoval(group, relPath)
+		}
+		return
+	}
+	synced, err := syncFile(group, filepath.ToSlash(relPath), copies, last, known)
+	if err != nil {
+		log.Printf("Error syncing %s: %v", relPath, err)
+		return
+	}
+	record(group, synced)
+}
+
+// propagateDir syncs the files of a directory in any of the roots, which
+// may have been filled before it was watched.
+func propagateDir(group root.MirrorGroup, ignore *Ignore, relDir string) {
+	ignored := func(rel string, isDir bool) bool {
+		return ignore.Match(filepath.Join(relDir, rel), isDir)
+	}
+	names := map[string]struct{}{}
+	for _, root := range group.Paths {
+		files, err := scanRoot(filepath.Join(root, relDir), ignored)
+		if err != nil {
+			continue
+		}
+		for rel := range files {
+			names[rel] = struct{}{}
+		}
+	}
+	for rel := range names {
+		propagate(group, filepath.Join(relDir, filepath.FromSlash(rel)))
+	}
+}
+
+// propagateRemoval removes a file, or a directory of files, deleted from one
+// root from the others.
+func propagateRemoval(group root.MirrorGroup, relPath string) {
+	last, known := state.File(group.Paths, relPath)
+	if !known {
+		removed, err := state.RemoveDir(group.Paths, relPath)
+		if err != nil {
+			log.Printf("Error saving mirror state: %v", err)
+		}
+		if len(removed) == 0 {
+			return
+		}
+		for _, root := range group.Paths {
+			if err := os.RemoveAll(filepath.Join(root, relPath)); err != nil {
+				log.Printf("Error removing %s: %v", filepath.Join(root, relPath), err)
+			}
+		}
+		return
+	}
+	if err := state.Record(group.Paths, relPath, nil); err != nil {
+		log.Printf("Error saving mirror state: %v", err)
+	}
+	synced, err := removeFile(group, filepath.ToSlash(relPath), last)
+	if err != nil {
+		log.Printf("Error removing %s: %v", relPath, err)
+		return
+	}
+	record(group, synced)
+}
+
+func record(group root.MirrorGroup, synced map[string]FileState) {
+	for name, file := range synced {
+		if err := state.Record(group.Paths, name, &file); err != nil {
+			log.Printf("Error saving mirror state: %v", err)
+		}
+	}
+}
+
 func findSourceRoot(roots []string, path string) string {
 	for _, root := range roots {
 		if strings.HasPrefix(path, root) {
@@ -110,11 +292,16 @@ func findSourceRoot(roots []string, path string) string {
 	return ""
 }
 
-func addRecursive(watcher *fsnotify.Watcher, path string) {
+// addRecursive watches path and the directories under it, except the ones
+// ignored relative to root.
+func addRecursive(watcher *fsnotify.Watcher, root, path string, ignore *Ignore) {
 	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
 		if err != nil || !info.IsDir() {
 			return nil
 		}
+		if rel, err := filepath.Rel(root, p); err == nil && ignore.Match(rel, true) {
+			return filepath.SkipDir
+		}
 		if _, ok := watched[p]; ok {
 			return nil
 		}
@@ -127,28 +314,3 @@ func addRecursive(watcher *fsnotify.Watcher, path string) {
 		return nil
 	})
 }
-
-func copyFile(src, dst string) {
-	err := os.MkdirAll(filepath.Dir(dst), 0755)
-	if err != nil {
-		log.Printf("Erro criando diretório: %v", err)
-		return
-	}
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return
-	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		log.Printf("Erro criando destino: %v", err)
-		return
-	}
-	defer dstFile.Close()
-
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
-		log.Printf("Erro copiando %s → %s: %v", src, dst, err)
-	}
-}
@@ -0,0 +1,128 @@
+package mirror
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/core-stack/zetten-cli/internal/core/root"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueEvents(t *testing.T) {
+	now := time.Now()
+	pending := map[string]time.Time{
+		"b":     now.Add(-time.Second),
+		"a":     now,
+		"later": now.Add(time.Second),
+	}
+	assert.Equal(t, []string{"a", "b"}, dueEvents(pending, now))
+	assert.Len(t, pending, 1)
+}
+
+func TestPropagateBatch_DeletesFromOtherRoots(t *testing.T) {
+	dir := t.TempDir()
+	group := root.MirrorGroup{Paths: []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}}
+	makeRoots(t, group.Paths...)
+	var err error
+	state, err = LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+	writeAt(t, filepath.Join(dir, "a", "sub", "file.txt"), "x", time.Now())
+	require.NoError(t, Reconcile(group, state))
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "a", "sub")))
+	require.NoError(t, state.Batch(func() {
+		propagateBatch(group, nil, []string{"sub", filepath.Join("sub", "file.txt")})
+	}))
+
+	assert.NoFileExists(t, filepath.Join(dir, "b", "sub", "file.txt"))
+	assert.Empty(t, state.Files(group.Paths))
+}
+
+// syncBuffer collects the log of the watcher goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchGroup_DebouncesWrites(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	group := root.MirrorGroup{Paths: []string{a, b}, Debounce: 100 * time.Millisecond}
+	makeRoots(t, group.Paths...)
+	var err error
+	state, err = LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+	require.NoError(t, Reconcile(group, state))
+
+	logs := &syncBuffer{}
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	wg.Add(1)
+	go watchGroup(group)
+	t.Cleanup(func() {
+		close(stopCh)
+		wg.Wait()
+		stopCh = make(chan struct{})
+	})
+	time.Sleep(50 * time.Millisecond)
+
+	// a save written in several chunks is only propagated once complete
+	f, err := os.Create(filepath.Join(a, "doc.txt"))
+	require.NoError(t, err)
+	for _, chunk := range []string{"one ", "two ", "three"} {
+		_, err := f.WriteString(chunk)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+	}
+	require.NoError(t, f.Close())
+
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(filepath.Join(b, "doc.txt"))
+		return err == nil && string(data) == "one two three"
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// neither the chunks nor the echo of the copy in b were propagated
+	time.Sleep(3 * group.Debounce)
+	assert.Equal(t, 1, strings.Count(logs.String(), "Copying "), logs.String())
+
+	leftovers, err := filepath.Glob(filepath.Join(b, TEMP_PREFIX+"*"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestPropagateBatch_SkipsMissingRoot(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	group := root.MirrorGroup{Paths: []string{a, b}}
+	makeRoots(t, group.Paths...)
+	var err error
+	state, err = LoadState(filepath.Join(dir, "state.yml"))
+	require.NoError(t, err)
+	writeAt(t, filepath.Join(a, "file.txt"), "x", time.Now())
+	require.NoError(t, Reconcile(group, state))
+
+	require.NoError(t, os.RemoveAll(b))
+	propagateBatch(group, nil, []string{"file.txt"})
+
+	assert.FileExists(t, filepath.Join(a, "file.txt"))
+}
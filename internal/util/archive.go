@@ -0,0 +1,189 @@
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var (
+	ErrUnsafeArchivePath = errors.New("unsafe path in archive")
+	ErrArchiveTooLarge   = errors.New("archive too large")
+)
+
+// MAX_ARCHIVE_SIZE caps the total size of the files extracted from an
+// archive and MAX_ARCHIVE_ENTRY_SIZE the size of each of them.
+var (
+	MAX_ARCHIVE_SIZE       int64 = 512 << 20
+	MAX_ARCHIVE_ENTRY_SIZE int64 = 128 << 20
+)
+
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// ArchiveExtension returns the archive extension of a package url, or an
+// empty string when it does not point to a supported archive.
+func ArchiveExtension(target string) string {
+	name := target
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" {
+		name = u.Path
+	}
+	name = strings.ToLower(name)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// IsArchive reports whether a package url points to a .tar.gz or .zip file.
+func IsArchive(target string) bool {
+	return ArchiveExtension(target) != ""
+}
+
+// ExtractArchive reads the regular files of a .tar.gz or .zip archive, keyed
+// by their slash separated path. Entries escaping the archive root are
+// rejected; links and special files are skipped. When every file lives in a
+// single top-level directory, as in most release artifacts, it is stripped.
+func ExtractArchive(ext string, data []byte) (map[string][]byte, error) {
+	var files map[string][]byte
+	var err error
+	switch ext {
+	case ".tar.gz", ".tgz":
+		files, err = extractTarGz(data)
+	case ".zip":
+		files, err = extractZip(data)
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stripCommonRoot(files), nil
+}
+
+// ReadAllLimited reads r until EOF, failing with ErrArchiveTooLarge when it
+// holds more than limit bytes.
+func ReadAllLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, limit)
+	}
+	return data, nil
+}
+
+// readEntry reads an archive entry within the per entry cap and what is
+// left of the total one.
+func readEntry(r io.Reader, name string, remaining *int64) ([]byte, error) {
+	content, err := ReadAllLimited(r, min(MAX_ARCHIVE_ENTRY_SIZE, *remaining))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	*remaining -= int64(len(content))
+	return content, nil
+}
+
+// archivePath validates and cleans the name of an archive entry.
+func archivePath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':') {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+		}
+	}
+	return path.Clean(name), nil
+}
+
+func extractTarGz(data []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	remaining := MAX_ARCHIVE_SIZE
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		name, err := archivePath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := readEntry(tr, name, &remaining)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+}
+
+func extractZip(data []byte) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	files := map[string][]byte{}
+	remaining := MAX_ARCHIVE_SIZE
+	for _, f := range zr.File {
+		name, err := archivePath(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := readEntry(rc, name, &remaining)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+	return files, nil
+}
+
+func stripCommonRoot(files map[string][]byte) map[string][]byte {
+	root := ""
+	for name := range files {
+		dir, _, found := strings.Cut(name, "/")
+		if !found || (root != "" && dir != root) {
+			return files
+		}
+		root = dir
+	}
+	if root == "" {
+		return files
+	}
+	stripped := make(map[string][]byte, len(files))
+	for name, data := range files {
+		stripped[strings.TrimPrefix(name, root+"/")] = data
+	}
+	return stripped
+}
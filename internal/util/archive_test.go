@@ -0,0 +1,79 @@
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		tw.Write([]byte(content))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestArchiveExtension(t *testing.T) {
+	assert.Equal(t, ".tar.gz", ArchiveExtension("https://example.com/ui-1.0.0.tar.gz"))
+	assert.Equal(t, ".zip", ArchiveExtension("../dist/UI.ZIP"))
+	assert.Equal(t, ".tgz", ArchiveExtension("https://example.com/ui.tgz?token=x"))
+	assert.Equal(t, "", ArchiveExtension("https://github.com/org/ui.git"))
+}
+
+func TestExtractArchive_TarGzStripsRoot(t *testing.T) {
+	files, err := ExtractArchive(".tar.gz", tarGz(t, map[string]string{
+		"ui-1.0.0/button.go":     "button",
+		"ui-1.0.0/css/theme.css": "theme",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"button.go": []byte("button"), "css/theme.css": []byte("theme")}, files)
+}
+
+func TestExtractArchive_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{"a.go": "a", "b/c.go": "c"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	require.NoError(t, zw.Close())
+
+	files, err := ExtractArchive(".zip", buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a.go": []byte("a"), "b/c.go": []byte("c")}, files)
+}
+
+func TestExtractArchive_RejectsTraversal(t *testing.T) {
+	for _, name := range []string{"../evil.sh", "ui/../../evil.sh", "/etc/passwd"} {
+		_, err := ExtractArchive(".tar.gz", tarGz(t, map[string]string{name: "x"}))
+		assert.ErrorIs(t, err, ErrUnsafeArchivePath, name)
+	}
+}
+
+func TestExtractArchive_RejectsOversized(t *testing.T) {
+	originalTotal, originalEntry := MAX_ARCHIVE_SIZE, MAX_ARCHIVE_ENTRY_SIZE
+	defer func() { MAX_ARCHIVE_SIZE, MAX_ARCHIVE_ENTRY_SIZE = originalTotal, originalEntry }()
+	MAX_ARCHIVE_SIZE, MAX_ARCHIVE_ENTRY_SIZE = 10, 6
+
+	_, err := ExtractArchive(".tar.gz", tarGz(t, map[string]string{"big.go": "1234567"}))
+	assert.ErrorIs(t, err, ErrArchiveTooLarge, "entry over the entry cap")
+
+	_, err = ExtractArchive(".tar.gz", tarGz(t, map[string]string{"a.go": "123456", "b.go": "123456"}))
+	assert.ErrorIs(t, err, ErrArchiveTooLarge, "entries over the total cap")
+
+	files, err := ExtractArchive(".tar.gz", tarGz(t, map[string]string{"a.go": "12345", "b.go": "12345"}))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
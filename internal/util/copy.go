@@ -4,11 +4,35 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 )
 
 // CopyDir recursively copies a directory tree from source to target, skipping ignored names.
 func CopyDir(source, target string, ignore []string) error {
+	return PlaceDir(source, target, ignore, CopyFile)
+}
+
+// LinkDir recreates the directory tree of source in target with every file
+// hard linked rather than copied.
+func LinkDir(source, target string, ignore []string) error {
+	return PlaceDir(source, target, ignore, LinkFile)
+}
+
+// PlaceDir recreates the directory tree of source in target, skipping ignored
+// names, and places every file with place.
+func PlaceDir(source, target string, ignore []string, place func(src, dst string) error) error {
+	return placeDir(source, target, "", ignoreNames(ignore), place)
+}
+
+// ignoreNames skips the slash separated paths whose name is ignored.
+func ignoreNames(ignore []string) func(rel string) bool {
+	return func(rel string) bool {
+		return isIgnored(path.Base(rel), ignore)
+	}
+}
+
+func placeDir(source, target, rel string, skip func(rel string) bool, place func(src, dst string) error) error {
 	entries, err := os.ReadDir(source)
 	if err != nil {
 		return fmt.Errorf("error reading source directory %s: %w", source, err)
@@ -20,8 +44,9 @@ func CopyDir(source, target string, ignore []string) error {
 
 	for _, entry := range entries {
 		name := entry.Name()
+		entryRel := path.Join(rel, name)
 
-		if isIgnored(name, ignore) {
+		if skip(entryRel) {
 			continue
 		}
 
@@ -34,11 +59,11 @@ func CopyDir(source, target string, ignore []string) error {
 		}
 
 		if info.IsDir() {
-			if err := CopyDir(srcPath, dstPath, ignore); err != nil {
+			if err := placeDir(srcPath, dstPath, entryRel, skip, place); err != nil {
 				return err
 			}
 		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
+			if err := place(srcPath, dstPath); err != nil {
 				return fmt.Errorf("error copying file from %s to %s: %w", srcPath, dstPath, err)
 			}
 		}
@@ -47,6 +72,50 @@ func CopyDir(source, target string, ignore []string) error {
 	return nil
 }
 
+// LinkFile hard links src to dst, copying it when linking is not possible,
+// for instance across devices.
+func LinkFile(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(src, dst); err != nil {
+		return CopyFile(src, dst)
+	}
+	return nil
+}
+
+// ReflinkFile clones src to dst sharing its blocks on filesystems that
+// support it, copying it otherwise. Unlike a hard link the clone is an
+// independent file, so it is made writable by its owner.
+func ReflinkFile(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := cloneFile(src, dst); err != nil {
+		if err := CopyFile(src, dst); err != nil {
+			return err
+		}
+	}
+	return makeWritable(dst)
+}
+
+// CopyFileWritable copies src to dst like CopyFile and makes the copy
+// writable by its owner, for sources that are read-only such as the store.
+func CopyFileWritable(src, dst string) error {
+	if err := CopyFile(src, dst); err != nil {
+		return err
+	}
+	return makeWritable(dst)
+}
+
+func makeWritable(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(path, fi.Mode()|0200)
+}
+
 func isIgnored(name string, ignore []string) bool {
 	for _, s := range ignore {
 		if s == name {
@@ -56,13 +125,18 @@ func isIgnored(name string, ignore []string) bool {
 	return false
 }
 
-func copyFile(src, dst string) error {
+// CopyFile copies a single file, keeping its permissions.
+func CopyFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer in.Close()
 
+	// replace rather than truncate, dst may be a hard link into a snapshot
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 	out, err := os.Create(dst)
 	if err != nil {
 		return err
@@ -79,3 +153,108 @@ func copyFile(src, dst string) error {
 
 	return nil
 }
+
+// SyncDir makes target an exact copy of source: files are copied as in
+// CopyDir and entries of target missing from source are removed. Ignored
+// names are neither copied nor removed.
+func SyncDir(source, target string, ignore []string) error {
+	return SyncDirFunc(source, target, ignoreNames(ignore))
+}
+
+// SyncDirFunc is SyncDir leaving alone the slash separated paths, relative
+// to source and target, for which skip returns true.
+func SyncDirFunc(source, target string, skip func(rel string) bool) error {
+	if err := placeDir(source, target, "", skip, CopyFile); err != nil {
+		return err
+	}
+	return removeExtraneous(source, target, "", skip)
+}
+
+func removeExtraneous(source, target, rel string, skip func(rel string) bool) error {
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		return fmt.Errorf("error reading target directory %s: %w", target, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		entryRel := path.Join(rel, name)
+		if skip(entryRel) {
+			continue
+		}
+		srcPath := filepath.Join(source, name)
+		dstPath := filepath.Join(target, name)
+
+		info, err := os.Stat(srcPath)
+		if os.IsNotExist(err) || (err == nil && info.IsDir() != entry.IsDir()) {
+			if err := os.RemoveAll(dstPath); err != nil {
+				return fmt.Errorf("error removing %s: %w", dstPath, err)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if err := removeExtraneous(srcPath, dstPath, entryRel, skip); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReplaceDir rebuilds target from scratch: build populates a temporary
+// sibling directory which then atomically takes the place of target. On
+// failure target is left untouched.
+func ReplaceDir(target string, build func(tmp string) error) error {
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("error creating directory %s: %w", parent, err)
+	}
+	tmp, err := os.MkdirTemp(parent, "."+filepath.Base(target)+"-new-")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := build(tmp); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+
+	return swap(tmp, target)
+}
+
+// ReplaceWithSymlink atomically replaces target with a symbolic link to
+// source.
+func ReplaceWithSymlink(target, source string) error {
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("error creating directory %s: %w", parent, err)
+	}
+	tmp := filepath.Join(parent, fmt.Sprintf(".%s-link-%d", filepath.Base(target), os.Getpid()))
+	os.Remove(tmp)
+	if err := os.Symlink(source, tmp); err != nil {
+		return fmt.Errorf("error linking %s: %w", source, err)
+	}
+	defer os.Remove(tmp)
+	return swap(tmp, target)
+}
+
+// swap moves tmp to target, removing what target held before.
+func swap(tmp, target string) error {
+	if _, err := os.Lstat(target); os.IsNotExist(err) {
+		return os.Rename(tmp, target)
+	}
+	old := tmp + "-old"
+	if err := os.Rename(target, old); err != nil {
+		return fmt.Errorf("error moving %s aside: %w", target, err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Rename(old, target)
+		return fmt.Errorf("error replacing %s: %w", target, err)
+	}
+	return os.RemoveAll(old)
+}
@@ -0,0 +1,192 @@
+package util
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+type MergeLabels struct {
+	Ours   string
+	Theirs string
+}
+
+// Merge3 performs a line based three-way merge of ours and theirs against
+// their common ancestor base. Hunks changed on both sides in different ways
+// are written with git-style conflict markers and reported through conflict.
+func Merge3(base, ours, theirs string, labels MergeLabels) (merged string, conflict bool) {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+	oursMatch := matchLines(base, ours)
+	theirsMatch := matchLines(base, theirs)
+
+	var out strings.Builder
+	i, j, k := 0, 0, 0
+	for {
+		// find the next base line kept unchanged on both sides
+		b, jo, kt := len(baseLines), len(oursLines), len(theirsLines)
+		for n := i; n < len(baseLines); n++ {
+			o, okO := oursMatch[n]
+			t, okT := theirsMatch[n]
+			if okO && okT && o >= j && t >= k {
+				b, jo, kt = n, o, t
+				break
+			}
+		}
+
+		baseChunk := baseLines[i:b]
+		oursChunk := oursLines[j:jo]
+		theirsChunk := theirsLines[k:kt]
+		switch {
+		case slices.Equal(oursChunk, baseChunk):
+			writeLines(&out, theirsChunk)
+		case slices.Equal(theirsChunk, baseChunk), slices.Equal(oursChunk, theirsChunk):
+			writeLines(&out, oursChunk)
+		default:
+			conflict = true
+			out.WriteString("<<<<<<< " + labels.Ours + "\n")
+			writeLines(&out, ensureTrailingNewline(oursChunk))
+			out.WriteString("=======\n")
+			writeLines(&out, ensureTrailingNewline(theirsChunk))
+			out.WriteString(">>>>>>> " + labels.Theirs + "\n")
+		}
+
+		if b == len(baseLines) {
+			break
+		}
+		out.WriteString(baseLines[b])
+		i, j, k = b+1, jo+1, kt+1
+	}
+	return out.String(), conflict
+}
+
+// matchLines maps the index of every base line that is kept in other to its
+// index in other.
+func matchLines(base, other string) map[int]int {
+	matches := map[int]int{}
+	i, j := 0, 0
+	for _, d := range diff.Do(base, other) {
+		n := len(splitLines(d.Text))
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			for x := 0; x < n; x++ {
+				matches[i+x] = j + x
+			}
+			i += n
+			j += n
+		case diffmatchpatch.DiffDelete:
+			i += n
+		case diffmatchpatch.DiffInsert:
+			j += n
+		}
+	}
+	return matches
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func ensureTrailingNewline(lines []string) []string {
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		lines = append(lines[:len(lines)-1:len(lines)-1], lines[len(lines)-1]+"\n")
+	}
+	return lines
+}
+
+func writeLines(b *strings.Builder, lines []string) {
+	for _, l := range lines {
+		b.WriteString(l)
+	}
+}
+
+// MergedFile is the result of merging a single path. Deleted marks paths
+// removed by the merge.
+type MergedFile struct {
+	Data    []byte
+	Deleted bool
+}
+
+// MergeFiles three-way merges sets of files keyed by path. The result holds
+// every path whose merged content differs from theirs; conflicting paths
+// are listed in conflicts and keep either conflict markers or, for binary
+// files and delete/modify conflicts, the content of ours.
+func MergeFiles(base, ours, theirs map[string][]byte, labels MergeLabels) (map[string]MergedFile, []string) {
+	paths := map[string]struct{}{}
+	for _, files := range []map[string][]byte{base, ours, theirs} {
+		for path := range files {
+			paths[path] = struct{}{}
+		}
+	}
+	same := func(x []byte, xOk bool, y []byte, yOk bool) bool {
+		return xOk == yOk && bytes.Equal(x, y)
+	}
+
+	result := map[string]MergedFile{}
+	var conflicts []string
+	for path := range paths {
+		b, inBase := base[path]
+		o, inOurs := ours[path]
+		t, inTheirs := theirs[path]
+
+		switch {
+		case same(o, inOurs, b, inBase), same(o, inOurs, t, inTheirs):
+			continue
+		case same(t, inTheirs, b, inBase):
+			result[path] = MergedFile{Data: o, Deleted: !inOurs}
+		case inBase && inOurs && inTheirs && !IsBinary(b) && !IsBinary(o) && !IsBinary(t):
+			merged, conflict := Merge3(string(b), string(o), string(t), labels)
+			result[path] = MergedFile{Data: []byte(merged)}
+			if conflict {
+				conflicts = append(conflicts, path)
+			}
+		default:
+			result[path] = MergedFile{Data: o, Deleted: !inOurs}
+			conflicts = append(conflicts, path)
+		}
+	}
+	sort.Strings(conflicts)
+	return result, conflicts
+}
+
+func IsBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// WriteMergedFiles applies the result of MergeFiles to dir.
+func WriteMergedFiles(dir string, files map[string]MergedFile) error {
+	for path, f := range files {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if f.Deleted {
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		// never write through a hard link into a shared snapshot
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.WriteFile(target, f.Data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
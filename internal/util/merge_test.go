@@ -0,0 +1,36 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	labels := MergeLabels{Ours: "local", Theirs: "v2"}
+	base := "a\nb\nc\nd\n"
+
+	t.Run("non overlapping changes", func(t *testing.T) {
+		merged, conflict := Merge3(base, "A\nb\nc\nd\n", "a\nb\nc\nD\n", labels)
+		assert.False(t, conflict)
+		assert.Equal(t, "A\nb\nc\nD\n", merged)
+	})
+
+	t.Run("same change on both sides", func(t *testing.T) {
+		merged, conflict := Merge3(base, "a\nB\nc\nd\n", "a\nB\nc\nd\n", labels)
+		assert.False(t, conflict)
+		assert.Equal(t, "a\nB\nc\nd\n", merged)
+	})
+
+	t.Run("insertions and deletions", func(t *testing.T) {
+		merged, conflict := Merge3(base, "a\nb\nc\nnew\nd\n", "a\nc\nd\n", labels)
+		assert.False(t, conflict)
+		assert.Equal(t, "a\nc\nnew\nd\n", merged)
+	})
+
+	t.Run("conflicting changes", func(t *testing.T) {
+		merged, conflict := Merge3(base, "a\nours\nc\nd\n", "a\ntheirs\nc\nd\n", labels)
+		assert.True(t, conflict)
+		assert.Equal(t, "a\n<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> v2\nc\nd\n", merged)
+	})
+}
@@ -0,0 +1,360 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const patchContext = 3
+
+var ErrPatchDoesNotApply = errors.New("patch does not apply")
+
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Lines holds the hunk body, each line prefixed by ' ', '-' or '+' and
+	// including its line ending.
+	Lines []string
+}
+
+// FilePatch is the unified diff of a single file. A path equal to
+// "/dev/null" marks an added or deleted file.
+type FilePatch struct {
+	OldPath string
+	NewPath string
+	Hunks   []Hunk
+}
+
+func (f *FilePatch) Path() string {
+	if f.NewPath == "/dev/null" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// DiffFiles returns a git-style unified diff turning the old set of files
+// into the new one. Binary files cannot be represented and are returned in
+// skipped instead.
+func DiffFiles(old, new map[string][]byte) (patch string, skipped []string) {
+	paths := map[string]struct{}{}
+	for path := range old {
+		paths[path] = struct{}{}
+	}
+	for path := range new {
+		paths[path] = struct{}{}
+	}
+	sorted := MapKeys(paths)
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	for _, path := range sorted {
+		o, inOld := old[path]
+		n, inNew := new[path]
+		if inOld && inNew && string(o) == string(n) {
+			continue
+		}
+		if IsBinary(o) || IsBinary(n) {
+			skipped = append(skipped, path)
+			continue
+		}
+
+		oldPath, newPath := "a/"+path, "b/"+path
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n", path, path)
+		if !inOld {
+			oldPath = "/dev/null"
+			b.WriteString("new file mode 100644\n")
+		}
+		if !inNew {
+			newPath = "/dev/null"
+			b.WriteString("deleted file mode 100644\n")
+		}
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldPath, newPath)
+		for _, h := range diffHunks(string(o), string(n)) {
+			writeHunk(&b, h)
+		}
+	}
+	return b.String(), skipped
+}
+
+func diffHunks(old, new string) []Hunk {
+	var lines []string
+	for _, d := range diff.Do(old, new) {
+		prefix := " "
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		}
+		for _, l := range splitLines(d.Text) {
+			lines = append(lines, prefix+l)
+		}
+	}
+
+	// oldBefore[i] and newBefore[i] count the lines preceding lines[i]
+	oldBefore := make([]int, len(lines)+1)
+	newBefore := make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		if l[0] != '+' {
+			oldBefore[i+1]++
+		}
+		if l[0] != '-' {
+			newBefore[i+1]++
+		}
+	}
+
+	var hunks []Hunk
+	for i := 0; i < len(lines); {
+		if lines[i][0] == ' ' {
+			i++
+			continue
+		}
+		start := max(0, i-patchContext)
+		end := i
+		for j := i; j < len(lines); {
+			if lines[j][0] != ' ' {
+				end = j
+				j++
+				continue
+			}
+			k := j
+			for k < len(lines) && lines[k][0] == ' ' {
+				k++
+			}
+			if k == len(lines) || k-j > 2*patchContext {
+				break
+			}
+			j = k
+		}
+		stop := min(len(lines), end+1+patchContext)
+
+		h := Hunk{
+			OldLines: oldBefore[stop] - oldBefore[start],
+			NewLines: newBefore[stop] - newBefore[start],
+			Lines:    lines[start:stop],
+		}
+		h.OldStart = hunkStart(oldBefore[start], h.OldLines)
+		h.NewStart = hunkStart(newBefore[start], h.NewLines)
+		hunks = append(hunks, h)
+		i = stop
+	}
+	return hunks
+}
+
+func hunkStart(before, count int) int {
+	if count == 0 {
+		return before
+	}
+	return before + 1
+}
+
+func writeHunk(b *strings.Builder, h Hunk) {
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+	for _, l := range h.Lines {
+		b.WriteString(l)
+		if !strings.HasSuffix(l, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// ParsePatch reads a unified diff as written by DiffFiles or git diff.
+// Lines outside of file sections, such as comments, are ignored.
+func ParsePatch(text string) ([]FilePatch, error) {
+	var patches []FilePatch
+	var current *FilePatch
+	lines := splitLines(text)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			patches = append(patches, FilePatch{
+				OldPath: patchPath(line[4:], "a/"),
+				NewPath: patchPath(lines[i+1][4:], "b/"),
+			})
+			current = &patches[len(patches)-1]
+			i++
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				return nil, fmt.Errorf("hunk without file header: %s", strings.TrimSpace(line))
+			}
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			oldLeft, newLeft := h.OldLines, h.NewLines
+			for oldLeft > 0 || newLeft > 0 {
+				i++
+				if i >= len(lines) {
+					return nil, fmt.Errorf("truncated hunk in %s", current.Path())
+				}
+				l := lines[i]
+				if l == "\n" {
+					// some editors strip the space of empty context lines
+					l = " \n"
+				}
+				switch l[0] {
+				case ' ':
+					oldLeft--
+					newLeft--
+				case '-':
+					oldLeft--
+				case '+':
+					newLeft--
+				case '\\':
+					if last := len(h.Lines) - 1; last >= 0 {
+						h.Lines[last] = strings.TrimSuffix(h.Lines[last], "\n")
+					}
+					continue
+				default:
+					return nil, fmt.Errorf("invalid hunk line in %s: %s", current.Path(), strings.TrimSpace(l))
+				}
+				h.Lines = append(h.Lines, l)
+			}
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\\") {
+				i++
+				last := len(h.Lines) - 1
+				h.Lines[last] = strings.TrimSuffix(h.Lines[last], "\n")
+			}
+			current.Hunks = append(current.Hunks, h)
+		}
+	}
+	return patches, nil
+}
+
+func patchPath(s, prefix string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexByte(s, '\t'); idx >= 0 {
+		s = s[:idx]
+	}
+	if s == "/dev/null" {
+		return s
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+func parseHunkHeader(line string) (Hunk, error) {
+	var h Hunk
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" {
+		return h, fmt.Errorf("invalid hunk header: %s", strings.TrimSpace(line))
+	}
+	var err error
+	if h.OldStart, h.OldLines, err = parseRange(fields[1], "-"); err != nil {
+		return h, err
+	}
+	if h.NewStart, h.NewLines, err = parseRange(fields[2], "+"); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+func parseRange(s, prefix string) (int, int, error) {
+	if !strings.HasPrefix(s, prefix) {
+		return 0, 0, fmt.Errorf("invalid hunk range: %s", s)
+	}
+	start, count, found := strings.Cut(s[1:], ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid hunk range: %s", s)
+	}
+	c := 1
+	if found {
+		if c, err = strconv.Atoi(count); err != nil {
+			return 0, 0, fmt.Errorf("invalid hunk range: %s", s)
+		}
+	}
+	return n, c, nil
+}
+
+// Apply applies the hunks to content. Hunks are located by their context,
+// so they still apply when upstream inserted or removed lines elsewhere.
+func (f *FilePatch) Apply(content string) (string, error) {
+	lines := splitLines(content)
+	offset := 0
+	for _, h := range f.Hunks {
+		var old, new []string
+		for _, l := range h.Lines {
+			if l[0] != '+' {
+				old = append(old, l[1:])
+			}
+			if l[0] != '-' {
+				new = append(new, l[1:])
+			}
+		}
+
+		expected := h.OldStart - 1 + offset
+		if h.OldLines == 0 {
+			expected = h.OldStart + offset
+		}
+		pos := findLines(lines, old, expected)
+		if pos < 0 {
+			return "", fmt.Errorf("%w: %s hunk @@ -%d,%d @@", ErrPatchDoesNotApply, f.Path(), h.OldStart, h.OldLines)
+		}
+		lines = append(lines[:pos:pos], append(new, lines[pos+len(old):]...)...)
+		offset += len(new) - len(old)
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// findLines returns the position of needle in lines closest to expected, or
+// -1 when it does not occur.
+func findLines(lines, needle []string, expected int) int {
+	matches := func(pos int) bool {
+		if pos < 0 || pos+len(needle) > len(lines) {
+			return false
+		}
+		for i := range needle {
+			if lines[pos+i] != needle[i] {
+				return false
+			}
+		}
+		return true
+	}
+	for delta := 0; delta <= len(lines); delta++ {
+		if matches(expected - delta) {
+			return expected - delta
+		}
+		if matches(expected + delta) {
+			return expected + delta
+		}
+	}
+	return -1
+}
+
+// ApplyPatch applies a multi file patch to files. It returns the new content
+// of every patched path and the paths whose hunks no longer apply, which are
+// left out of the result.
+func ApplyPatch(files map[string][]byte, patches []FilePatch) (map[string]MergedFile, []string) {
+	result := map[string]MergedFile{}
+	var conflicts []string
+	for _, p := range patches {
+		path := p.Path()
+		content, exists := files[path]
+		if p.OldPath == "/dev/null" && exists {
+			conflicts = append(conflicts, path)
+			continue
+		}
+		if p.OldPath != "/dev/null" && !exists {
+			conflicts = append(conflicts, path)
+			continue
+		}
+		patched, err := p.Apply(string(content))
+		if err != nil {
+			conflicts = append(conflicts, path)
+			continue
+		}
+		result[path] = MergedFile{Data: []byte(patched), Deleted: p.NewPath == "/dev/null"}
+	}
+	sort.Strings(conflicts)
+	return result, conflicts
+}
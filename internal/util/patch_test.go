@@ -0,0 +1,48 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFilesAndApplyPatch(t *testing.T) {
+	old := map[string][]byte{
+		"main.go":    []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"),
+		"removed.go": []byte("bye\n"),
+		"eof.txt":    []byte("no newline"),
+	}
+	new := map[string][]byte{
+		"main.go":  []byte("1\nTWO\n3\n4\n5\n6\n7\n8\n9\n10\nELEVEN\n12\n"),
+		"added.go": []byte("hello\n"),
+		"eof.txt":  []byte("still no newline"),
+	}
+
+	text, skipped := DiffFiles(old, new)
+	assert.Empty(t, skipped)
+	assert.Contains(t, text, "--- /dev/null\n+++ b/added.go\n@@ -0,0 +1,1 @@\n+hello\n")
+	assert.Contains(t, text, "-no newline\n\\ No newline at end of file\n")
+
+	patches, err := ParsePatch("# comment\n" + text)
+	require.NoError(t, err)
+	require.Len(t, patches, 4)
+
+	// upstream inserted lines at the top, the hunks still apply by context
+	upstream := map[string][]byte{
+		"main.go":    []byte("0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"),
+		"removed.go": []byte("bye\n"),
+		"eof.txt":    []byte("no newline"),
+	}
+	result, conflicts := ApplyPatch(upstream, patches)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "0\n1\nTWO\n3\n4\n5\n6\n7\n8\n9\n10\nELEVEN\n12\n", string(result["main.go"].Data))
+	assert.Equal(t, "hello\n", string(result["added.go"].Data))
+	assert.Equal(t, "still no newline", string(result["eof.txt"].Data))
+	assert.True(t, result["removed.go"].Deleted)
+
+	// upstream changed a patched line, the hunk no longer applies
+	upstream["main.go"] = []byte("1\n2 changed\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	_, conflicts = ApplyPatch(upstream, patches)
+	assert.Equal(t, []string{"main.go"}, conflicts)
+}
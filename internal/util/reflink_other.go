@@ -0,0 +1,10 @@
+//go:build !linux
+
+package util
+
+import "errors"
+
+// cloneFile is only supported on linux, callers fall back to copying.
+func cloneFile(src, dst string) error {
+	return errors.New("reflink is not supported on this platform")
+}
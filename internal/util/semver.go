@@ -0,0 +1,231 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. The "v" prefix of tags is optional.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Original   string
+}
+
+func ParseVersion(s string) (Version, error) {
+	v := Version{Original: s}
+	core := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if idx := strings.IndexByte(core, '+'); idx >= 0 {
+		core = core[:idx]
+	}
+	if idx := strings.IndexByte(core, '-'); idx >= 0 {
+		core, v.Prerelease = core[:idx], core[idx+1:]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, fmt.Errorf("invalid version: %s", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version: %s", s)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 when v is lower, equal or greater than other.
+// Pre-releases sort before their release.
+func (v Version) Compare(other Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+	return strings.Compare(v.Prerelease, other.Prerelease)
+}
+
+func (v Version) String() string {
+	if v.Original != "" {
+		return v.Original
+	}
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
+
+// SortVersions parses tags as versions, drops the ones that are not valid and
+// returns the rest in ascending order.
+func SortVersions(tags []string) []Version {
+	var versions []Version
+	for _, tag := range tags {
+		if v, err := ParseVersion(tag); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) < 0
+	})
+	return versions
+}
+
+// Constraint is a version range such as ^2, ~1.2, >=1.0.0 <2.0.0, 1.2.x or
+// an exact version. Space separated comparisons must all hold.
+type Constraint struct {
+	checks []func(Version) bool
+	raw    string
+}
+
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: s}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return c, fmt.Errorf("empty version constraint")
+	}
+	for _, field := range fields {
+		check, err := parseComparison(field)
+		if err != nil {
+			return c, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.checks = append(c.checks, check)
+	}
+	return c, nil
+}
+
+func parseComparison(s string) (func(Version) bool, error) {
+	if s == "*" || s == "x" || s == "latest" {
+		return func(Version) bool { return true }, nil
+	}
+	for _, op := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		bound, parts, err := parsePartial(s[len(op):])
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case ">=":
+			return func(v Version) bool { return v.Compare(bound) >= 0 }, nil
+		case "<=":
+			return func(v Version) bool { return v.Compare(bound) <= 0 }, nil
+		case ">":
+			return func(v Version) bool { return v.Compare(bound) > 0 }, nil
+		case "<":
+			return func(v Version) bool { return v.Compare(bound) < 0 }, nil
+		case "^":
+			// same major, or same minor below 1.0.0
+			upper := Version{Major: bound.Major + 1}
+			if bound.Major == 0 && parts > 1 {
+				upper = Version{Minor: bound.Minor + 1}
+			}
+			return between(bound, upper), nil
+		case "~":
+			upper := Version{Major: bound.Major, Minor: bound.Minor + 1}
+			if parts == 1 {
+				upper = Version{Major: bound.Major + 1}
+			}
+			return between(bound, upper), nil
+		default:
+			return partialMatch(bound, parts), nil
+		}
+	}
+	bound, parts, err := parsePartial(s)
+	if err != nil {
+		return nil, err
+	}
+	return partialMatch(bound, parts), nil
+}
+
+// parsePartial parses a possibly incomplete version such as 2, 1.2 or 1.2.x
+// and returns how many of its numbers were given.
+func parsePartial(s string) (Version, int, error) {
+	core := strings.TrimPrefix(s, "v")
+	parts := strings.Split(core, ".")
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			parts = parts[:i]
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return Version{}, 0, nil
+	}
+	if len(parts) == 3 {
+		v, err := ParseVersion(core)
+		return v, 3, err
+	}
+	v, err := ParseVersion(strings.Join(parts, "."))
+	return v, len(parts), err
+}
+
+func between(lower, upper Version) func(Version) bool {
+	return func(v Version) bool {
+		return v.Compare(lower) >= 0 && v.Compare(upper) < 0
+	}
+}
+
+func partialMatch(bound Version, parts int) func(Version) bool {
+	return func(v Version) bool {
+		if parts == 3 {
+			return v.Compare(bound) == 0
+		}
+		if parts >= 1 && v.Major != bound.Major {
+			return false
+		}
+		return parts < 2 || v.Minor == bound.Minor
+	}
+}
+
+// Check reports whether v satisfies c. Pre-releases only satisfy constraints
+// that mention one.
+func (c Constraint) Check(v Version) bool {
+	if v.Prerelease != "" && !strings.Contains(c.raw, "-") {
+		return false
+	}
+	for _, check := range c.checks {
+		if !check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// MaxSatisfying returns the highest tag satisfying c, or false when none does.
+func (c Constraint) MaxSatisfying(tags []string) (string, bool) {
+	versions := SortVersions(tags)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if c.Check(versions[i]) {
+			return versions[i].Original, true
+		}
+	}
+	return "", false
+}
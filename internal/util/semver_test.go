@@ -0,0 +1,59 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("v1.2.3-beta.1+build")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v.Major)
+	assert.Equal(t, 2, v.Minor)
+	assert.Equal(t, 3, v.Patch)
+	assert.Equal(t, "beta.1", v.Prerelease)
+	assert.Equal(t, "v1.2.3-beta.1+build", v.String())
+
+	v, err = ParseVersion("2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v.Patch)
+
+	_, err = ParseVersion("latest")
+	assert.Error(t, err)
+}
+
+func TestSortVersions(t *testing.T) {
+	versions := SortVersions([]string{"v1.10.0", "main", "v1.2.0", "v1.2.0-rc.1", "v0.9.9"})
+	var tags []string
+	for _, v := range versions {
+		tags = append(tags, v.String())
+	}
+	assert.Equal(t, []string{"v0.9.9", "v1.2.0-rc.1", "v1.2.0", "v1.10.0"}, tags)
+}
+
+func TestConstraint_MaxSatisfying(t *testing.T) {
+	tags := []string{"v1.0.0", "v1.2.0", "v1.2.3", "v2.0.0-beta.1", "v2.0.0", "v2.3.1", "v3.0.0", "latest-build"}
+	cases := map[string]string{
+		"^2":             "v2.3.1",
+		"^1.2":           "v1.2.3",
+		"~1.2":           "v1.2.3",
+		"1.x":            "v1.2.3",
+		"1.2.0":          "v1.2.0",
+		"v2.0.0":         "v2.0.0",
+		">=1.0.0 <2.0.0": "v1.2.3",
+		"*":              "v3.0.0",
+		">3":             "",
+	}
+	for spec, expected := range cases {
+		c, err := ParseConstraint(spec)
+		require.NoError(t, err, spec)
+		got, ok := c.MaxSatisfying(tags)
+		assert.Equal(t, expected, got, spec)
+		assert.Equal(t, expected != "", ok, spec)
+	}
+
+	_, err := ParseConstraint("^two")
+	assert.Error(t, err)
+}
@@ -1,7 +1,12 @@
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -69,6 +74,80 @@ func ExtractPathFromURL(targetUrl string) string {
 	return u.Path
 }
 
+// IsLocalPath reports whether a package url points to the local filesystem,
+// either as a file:// url or as an absolute or relative path.
+func IsLocalPath(target string) bool {
+	for _, prefix := range []string{"file://", "./", "../", "~/", ".\\", "..\\"} {
+		if strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+	return target == "." || target == ".." || filepath.IsAbs(target)
+}
+
+// LocalPath returns the filesystem path of a local package url, relative
+// paths being resolved against baseDir.
+func LocalPath(target, baseDir string) string {
+	p := strings.TrimPrefix(target, "file://")
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[2:])
+		}
+	}
+	p = filepath.FromSlash(p)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(baseDir, p)
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// PackageName returns the directory, relative to a packages path, that the
+// package at url is installed in. Local packages are grouped under "local"
+// and suffixed with a hash of their absolute path, so directories sharing a
+// name do not collide. Relative paths are resolved against the working
+// directory; pass them through LocalPath first.
+func PackageName(target string) string {
+	if IsLocalPath(target) {
+		abs := LocalPath(target, ".")
+		sum := sha256.Sum256([]byte(filepath.ToSlash(abs)))
+		base := trimPackageExt(filepath.Base(abs))
+		return path.Join("local", base+"-"+hex.EncodeToString(sum[:4]))
+	}
+	return trimPackageExt(ExtractPathFromURL(target))
+}
+
+func trimPackageExt(name string) string {
+	if ext := ArchiveExtension(name); ext != "" {
+		return name[:len(name)-len(ext)]
+	}
+	return strings.TrimSuffix(name, ".git")
+}
+
+// MatchPaths reports whether the slash separated path rel, or one of its
+// parent directories, matches a glob pattern. A pattern without a slash also
+// matches by file or directory name at any depth.
+func MatchPaths(patterns []string, rel string) bool {
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		prefix := strings.Join(parts[:i+1], "/")
+		for _, pattern := range patterns {
+			pattern = strings.Trim(pattern, "/")
+			if ok, _ := path.Match(pattern, prefix); ok {
+				return true
+			}
+			if !strings.Contains(pattern, "/") {
+				if ok, _ := path.Match(pattern, parts[i]); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func MapKeys[T map[K]V, K comparable, V any](m T) []K {
 	var keys []K
 	for k := range m {
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -171,3 +172,26 @@ func TestIsValidURL(t *testing.T) {
 		})
 	}
 }
+
+func TestIsLocalPath(t *testing.T) {
+	assert.True(t, IsLocalPath("file:///home/me/shared-ui"))
+	assert.True(t, IsLocalPath("../shared-ui"))
+	assert.True(t, IsLocalPath("./libs/ui"))
+	assert.True(t, IsLocalPath("/srv/git/ui.git"))
+	assert.False(t, IsLocalPath("github.com/org/ui"))
+	assert.False(t, IsLocalPath("https://github.com/org/ui.git"))
+	assert.False(t, IsLocalPath("git@github.com:org/ui.git"))
+}
+
+func TestLocalPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("/work", "shared-ui"), LocalPath("../shared-ui", "/work/app"))
+	assert.Equal(t, "/srv/ui", LocalPath("file:///srv/ui", "/work/app"))
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Regexp(t, `^local/shared-ui-[0-9a-f]{8}$`, PackageName("/work/shared-ui"))
+	assert.Regexp(t, `^local/ui-[0-9a-f]{8}$`, PackageName("file:///srv/git/ui.git"))
+	assert.NotEqual(t, PackageName("/a/ui"), PackageName("/b/ui"))
+	assert.Equal(t, PackageName("/srv/ui"), PackageName("file:///srv/ui"))
+	assert.Equal(t, "/org/ui", PackageName("https://github.com/org/ui.git"))
+}